@@ -8,9 +8,23 @@ import (
 // side effect imports for all built-in scanners
 import (
 	_ "github.com/mergestat/kyc/pkg/scanner/lang/golang"
+	_ "github.com/mergestat/kyc/pkg/scanner/lang/java/gradle"
+	_ "github.com/mergestat/kyc/pkg/scanner/lang/java/maven"
 	_ "github.com/mergestat/kyc/pkg/scanner/lang/node/npm"
+	_ "github.com/mergestat/kyc/pkg/scanner/lang/node/pnpm"
+	_ "github.com/mergestat/kyc/pkg/scanner/lang/node/yarn"
+	_ "github.com/mergestat/kyc/pkg/scanner/lang/python"
+	_ "github.com/mergestat/kyc/pkg/scanner/lang/ruby/bundler"
+	_ "github.com/mergestat/kyc/pkg/scanner/lang/rust/cargo"
 	_ "github.com/mergestat/kyc/pkg/scanner/meta/files"
+	_ "github.com/mergestat/kyc/pkg/scanner/tools/azure"
+	_ "github.com/mergestat/kyc/pkg/scanner/tools/circleci"
 	_ "github.com/mergestat/kyc/pkg/scanner/tools/docker"
+	_ "github.com/mergestat/kyc/pkg/scanner/tools/github"
+	_ "github.com/mergestat/kyc/pkg/scanner/tools/gitlab"
+	_ "github.com/mergestat/kyc/pkg/scanner/tools/helm"
+	_ "github.com/mergestat/kyc/pkg/scanner/tools/kubernetes"
+	_ "github.com/mergestat/kyc/pkg/scanner/tools/terraform"
 )
 
 func init() { sqlite.Register(kyc.ExtensionFunc()) }
@@ -215,6 +215,9 @@ func (cur *FactCursor) Filter(_ int, str string, values ...sqlite.Value) (err er
 		return sqlite.Error(sqlite.SQLITE_ERROR, err.Error())
 	}
 
+	// make the tree available to scanners that need to look beyond the file being scanned
+	ctx = scanner.WithTree(ctx, tree)
+
 	// TODO(@riyaz): explore async options for file scanning
 	// iterate over all files in the tree, and run all scanners against each file
 	err = tree.Files().ForEach(func(file *object.File) error {
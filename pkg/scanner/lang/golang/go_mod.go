@@ -7,12 +7,13 @@ import (
 	"github.com/mergestat/kyc/pkg/scanner"
 	"golang.org/x/mod/modfile"
 	"io"
+	"path"
 )
 
 type GoMod struct{}
 
 func (g *GoMod) Supports(file *object.File) bool {
-	return file.Mode.IsFile() && file.Name == "go.mod"
+	return file.Mode.IsFile() && path.Base(file.Name) == "go.mod"
 }
 
 func (g *GoMod) Scan(ctx context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
@@ -30,19 +31,68 @@ func (g *GoMod) Scan(ctx context.Context, file *object.File, _ ...string) (_ []s
 		return nil, err
 	}
 
+	// replace and exclude directives (among others) are only parsed in strict mode; but strict parsing also fails on
+	// directives unknown to our version of x/mod, in which case we fall back to lax parsing to extract whatever we can.
 	var module *modfile.File
-	if module, err = modfile.ParseLax(file.Name, content.Bytes(), nil /* version fixer */); err != nil {
-		return nil, err
+	if module, err = modfile.Parse(file.Name, content.Bytes(), nil /* version fixer */); err != nil {
+		if module, err = modfile.ParseLax(file.Name, content.Bytes(), nil /* version fixer */); err != nil {
+			return nil, err
+		}
+	}
+
+	if module.Module != nil {
+		// the module root is the directory containing the go.mod file, relative to the repository root
+		var val = map[string]any{"path": module.Module.Mod.Path, "root": path.Dir(file.Name)}
+		if module.Module.Deprecated != "" {
+			val["deprecated"] = module.Module.Deprecated
+		}
+		facts = append(facts, scanner.Fact{Key: "@golang/mod/module", Value: val})
+	}
+
+	if module.Go != nil {
+		facts = append(facts, scanner.Fact{Key: "@golang/mod/go", Value: map[string]any{"version": module.Go.Version}})
+	}
+
+	if module.Toolchain != nil {
+		facts = append(facts, scanner.Fact{Key: "@golang/mod/toolchain", Value: map[string]any{"name": module.Toolchain.Name}})
 	}
 
 	// for each require, emit a fact
 	for _, req := range module.Require {
-		var val = map[string]any{"path": req.Mod.Path, "version": req.Mod.Version}
+		var val = map[string]any{"path": req.Mod.Path, "version": req.Mod.Version, "indirect": req.Indirect}
 		facts = append(facts, scanner.Fact{Key: "@golang/mod/require", Value: val})
 	}
 
+	// for each replace, emit a fact
+	for _, rep := range module.Replace {
+		facts = append(facts, scanner.Fact{Key: "@golang/mod/replace", Value: replaceValue(rep)})
+	}
+
+	// for each exclude, emit a fact
+	for _, exc := range module.Exclude {
+		var val = map[string]any{"path": exc.Mod.Path, "version": exc.Mod.Version}
+		facts = append(facts, scanner.Fact{Key: "@golang/mod/exclude", Value: val})
+	}
+
+	// for each retract, emit a fact
+	for _, ret := range module.Retract {
+		var val = map[string]any{"low": ret.Low, "high": ret.High, "rationale": ret.Rationale}
+		facts = append(facts, scanner.Fact{Key: "@golang/mod/retract", Value: val})
+	}
+
 	return facts, nil
 }
 
+// replaceValue returns the fact value for a replace directive, which may appear in both go.mod and go.work files
+func replaceValue(rep *modfile.Replace) map[string]any {
+	return map[string]any{
+		"old_path": rep.Old.Path, "old_version": rep.Old.Version,
+		"new_path": rep.New.Path, "new_version": rep.New.Version,
+
+		// replacements without a version (on the right-hand side) point to a directory on the local filesystem
+		"local": rep.New.Version == "",
+	}
+}
+
 // register the GoMod with scanner registry
 func init() { scanner.Register("golang/mod", &GoMod{}) }
@@ -0,0 +1,170 @@
+package golang
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type GoSource struct{}
+
+func (g *GoSource) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && strings.HasSuffix(file.Name, ".go")
+}
+
+func (g *GoSource) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	// source files with syntax errors (such as the ones often found in testdata/) still produce a partial syntax tree,
+	// which is good enough to extract facts from; so we only fail if nothing could be parsed at all.
+	var fset = token.NewFileSet()
+	var source *ast.File
+	if source, err = parser.ParseFile(fset, file.Name, content.Bytes(), parser.ParseComments); source == nil {
+		return nil, err
+	} else if source.Name == nil || source.Name.Name == "" {
+		return nil, nil // not even the package clause could be parsed; most likely not a go file at all
+	}
+
+	var test = strings.HasSuffix(file.Name, "_test.go")
+	emit("@golang/source/package", map[string]any{"name": source.Name.Name, "test": test})
+
+	// for each import, emit a fact
+	for _, spec := range source.Imports {
+		var path, _ = strconv.Unquote(spec.Path.Value)
+		var val = map[string]any{"path": path, "stdlib": isStdlib(path), "test": test}
+		if spec.Name != nil {
+			val["alias"] = spec.Name.Name
+		}
+		emit("@golang/source/import", val)
+	}
+
+	// build constraints are only recognized in comments before the package clause;
+	// legacy // +build lines are only considered if there is no //go:build line
+	var goBuild, plusBuild []string
+	for _, group := range source.Comments {
+		if group.Pos() >= source.Package {
+			break
+		}
+
+		for _, comment := range group.List {
+			if expr, err := constraint.Parse(comment.Text); err == nil {
+				if constraint.IsGoBuild(comment.Text) {
+					goBuild = append(goBuild, expr.String())
+				} else {
+					plusBuild = append(plusBuild, expr.String())
+				}
+			}
+		}
+	}
+	if len(goBuild) == 0 {
+		goBuild = plusBuild
+	}
+	for _, expr := range goBuild {
+		emit("@golang/source/build-constraint", map[string]any{"expr": expr})
+	}
+
+	// //go:generate directives may appear anywhere in the file
+	for _, group := range source.Comments {
+		for _, comment := range group.List {
+			if command, ok := directive(comment.Text, "go:generate"); ok {
+				emit("@golang/source/generate", map[string]any{"command": command, "line": fset.Position(comment.Pos()).Line})
+			}
+		}
+	}
+
+	// //go:embed directives apply to the variable declared right after them
+	for _, decl := range source.Decls {
+		var gen, ok = decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			var spec = spec.(*ast.ValueSpec)
+
+			var doc = spec.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc // var x string, rather than var ( x string )
+			}
+			if doc == nil {
+				continue
+			}
+
+			var patterns []string
+			for _, comment := range doc.List {
+				if args, ok := directive(comment.Text, "go:embed"); ok {
+					patterns = append(patterns, embedPatterns(args)...)
+				}
+			}
+
+			if len(patterns) > 0 {
+				emit("@golang/source/embed", map[string]any{"var": spec.Names[0].Name, "patterns": patterns})
+			}
+		}
+	}
+
+	return facts, nil
+}
+
+// isStdlib reports whether the import path belongs to the standard library;
+// following the convention used by the go tool, paths whose first element does not contain a dot are standard.
+func isStdlib(path string) bool {
+	var elem, _, _ = strings.Cut(path, "/")
+	return path != "C" && !strings.Contains(elem, ".")
+}
+
+// directive returns the arguments of the //<name> directive, if the comment is one
+func directive(comment, name string) (string, bool) {
+	if rest := strings.TrimPrefix(comment, "//"+name); rest != comment && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+		return strings.TrimSpace(rest), true
+	}
+	return "", false
+}
+
+// embedPatterns splits the arguments of a //go:embed directive, which may be quoted
+func embedPatterns(args string) []string {
+	var patterns []string
+	for args = strings.TrimSpace(args); args != ""; args = strings.TrimSpace(args) {
+		var pattern string
+		if quote := args[0]; quote == '"' || quote == '`' {
+			var end = strings.IndexByte(args[1:], quote)
+			if end < 0 {
+				return append(patterns, args) // unterminated quote; best effort
+			}
+			pattern, args = args[:end+2], args[end+2:]
+			if unquoted, err := strconv.Unquote(pattern); err == nil {
+				pattern = unquoted
+			}
+		} else if i := strings.IndexAny(args, " \t"); i >= 0 {
+			pattern, args = args[:i], args[i:]
+		} else {
+			pattern, args = args, ""
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// register the GoSource with scanner registry
+func init() { scanner.Register("golang/source", &GoSource{}) }
@@ -0,0 +1,49 @@
+package golang
+
+import (
+	"bufio"
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"strings"
+)
+
+type GoSum struct{}
+
+func (g *GoSum) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == "go.sum"
+}
+
+func (g *GoSum) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// each line is of the form: <module> <version>[/go.mod] <hash>
+	// where the /go.mod suffix marks a checksum of the module's go.mod file only, rather than of the whole module tree
+	var lines = bufio.NewScanner(reader)
+	for lines.Scan() {
+		var fields = strings.Fields(lines.Text())
+		if len(fields) != 3 {
+			continue // blank or malformed line; the go command ignores these too
+		}
+
+		var version = strings.TrimSuffix(fields[1], "/go.mod")
+		var goMod = version != fields[1]
+
+		// path and version use the same names as in @golang/mod/require facts, so the two can be joined together
+		var val = map[string]any{"path": fields[0], "version": version, "go_mod": goMod, "hash": fields[2]}
+		facts = append(facts, scanner.Fact{Key: "@golang/sum/checksum", Value: val})
+	}
+
+	return facts, lines.Err()
+}
+
+// register the GoSum with scanner registry
+func init() { scanner.Register("golang/sum", &GoSum{}) }
@@ -0,0 +1,66 @@
+package golang
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"golang.org/x/mod/modfile"
+	"io"
+	"path"
+)
+
+type GoWork struct{}
+
+func (g *GoWork) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == "go.work"
+}
+
+func (g *GoWork) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	var work *modfile.WorkFile
+	if work, err = modfile.ParseWork(file.Name, content.Bytes(), nil /* version fixer */); err != nil {
+		return nil, err
+	}
+
+	if work.Go != nil {
+		facts = append(facts, scanner.Fact{Key: "@golang/work/go", Value: map[string]any{"version": work.Go.Version}})
+	}
+
+	if work.Toolchain != nil {
+		facts = append(facts, scanner.Fact{Key: "@golang/work/toolchain", Value: map[string]any{"name": work.Toolchain.Name}})
+	}
+
+	// for each use, emit a fact
+	for _, use := range work.Use {
+		// root is the module directory relative to the repository root, to match the root of @golang/mod/module facts
+		var val = map[string]any{"path": use.Path, "root": path.Join(path.Dir(file.Name), use.Path)}
+		if use.ModulePath != "" {
+			val["module_path"] = use.ModulePath // as recorded in the trailing comment by go work use
+		}
+		facts = append(facts, scanner.Fact{Key: "@golang/work/use", Value: val})
+	}
+
+	// for each replace, emit a fact
+	for _, rep := range work.Replace {
+		facts = append(facts, scanner.Fact{Key: "@golang/work/replace", Value: replaceValue(rep)})
+	}
+
+	return facts, nil
+}
+
+// register the GoWork with scanner registry
+func init() { scanner.Register("golang/work", &GoWork{}) }
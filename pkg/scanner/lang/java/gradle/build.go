@@ -0,0 +1,188 @@
+package gradle
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	// matches comments; line comments must be preceded by whitespace (or start the line), so that urls are left alone
+	comments = regexp.MustCompile(`(?s:/\*.*?\*/)|(^|\s)//[^\n]*`)
+
+	// matches dependencies in string notation, as in implementation("group:name:version") or testImplementation 'group:name',
+	// optionally wrapped in platform() or enforcedPlatform(), as in implementation(platform("group:name:version"))
+	// (group and name cannot contain a slash, so that urls such as maven("https://jitpack.io") are left alone)
+	stringNotation = regexp.MustCompile(`\b(\w+)\s*\(?\s*(?:(platform|enforcedPlatform)\s*\(\s*)?["']([^"'\s:/]+):([^"'\s:/]+)(?::([^"'\s:@]*))?(?::([^"'\s:@]+))?(?:@(\w+))?["']`)
+
+	// matches dependencies in map notation, as in implementation group: 'g', name: 'n', version: 'v' (or with = in kotlin)
+	mapNotation = regexp.MustCompile(`\b(\w+)\s*\(?\s*group\s*[:=]\s*["']([^"']+)["']\s*,\s*name\s*[:=]\s*["']([^"']+)["']\s*(?:,\s*version\s*[:=]\s*["']([^"']+)["'])?`)
+
+	// matches references to project dependencies, as in implementation(project(":core"))
+	projectNotation = regexp.MustCompile(`\b(\w+)\s*\(?\s*project\s*\(\s*(?:path\s*[:=]\s*)?["']([^"']+)["']`)
+
+	// matches references to version catalog entries, as in implementation(libs.spring.boot.starter)
+	catalogNotation = regexp.MustCompile(`\b(\w+)\s*\(?\s*(?:(platform|enforcedPlatform)\s*\(\s*)?(libs\.[\w.]+)`)
+
+	// matches plugins, as in id("org.springframework.boot") version "3.1.0", id 'java' or apply plugin: 'java'
+	pluginID     = regexp.MustCompile(`\bid\s*\(?\s*["']([\w.\-]+)["']\s*\)?(?:\s*version\s*\(?\s*["']([^"']+)["']\s*\)?)?`)
+	pluginKotlin = regexp.MustCompile(`\bkotlin\s*\(\s*["']([\w.\-]+)["']\s*\)(?:\s*version\s*\(?\s*["']([^"']+)["']\s*\)?)?`)
+	pluginApply  = regexp.MustCompile(`\bapply\s*\(?\s*plugin\s*[:=]\s*["']([\w.\-]+)["']`)
+	pluginAlias  = regexp.MustCompile(`\balias\s*\(\s*(libs\.plugins\.[\w.]+)\s*\)(?:\s*version\s*\(?\s*["']([^"']+)["']\s*\)?)?`)
+
+	// matches simple variable declarations, as in val kotlinVersion = "1.9.0", def springVersion = '3.1.0' or ext.x = 'y'
+	variable = regexp.MustCompile(`(?m)^\s*(?:(?:val|var|def)\s+|ext\.|extra\[)["']?(\w+)["']?\]?\s*=\s*["']([^"'$]+)["']`)
+
+	// matches variable references in string interpolation, as in $kotlinVersion or ${springVersion}
+	variableRef = regexp.MustCompile(`\$\{?(\w+)}?`)
+
+	// matches the opening of a dependencies { ... } or plugins { ... } block
+	dependenciesBlock = regexp.MustCompile(`\bdependencies\s*\{`)
+	pluginsBlock      = regexp.MustCompile(`\bplugins\s*\{`)
+)
+
+// keywords that look like configurations in string notation, but are not
+var notConfigurations = map[string]bool{"id": true, "kotlin": true, "version": true, "url": true, "uri": true, "alias": true, "plugin": true}
+
+// BuildScanner implements scanner.Scanner to extract facts from gradle build scripts, written in either groovy or kotlin.
+// As build scripts are programs, only the common declarative forms of dependencies and plugins are recognised.
+type BuildScanner struct{}
+
+func (b *BuildScanner) Supports(file *object.File) bool {
+	var name = path.Base(file.Name)
+	return file.Mode.IsFile() && (name == "build.gradle" || name == "build.gradle.kts")
+}
+
+func (b *BuildScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	var script = comments.ReplaceAllString(content.String(), "$1")
+
+	// versions are often extracted into variables, which are substituted if declared in the same script
+	var variables = make(map[string]string)
+	for _, match := range variable.FindAllStringSubmatch(script, -1) {
+		variables[match[1]] = match[2]
+	}
+	var versioned = func(val map[string]any, declared string) map[string]any {
+		val["version"] = variableRef.ReplaceAllStringFunc(declared, func(ref string) string {
+			if v, ok := variables[strings.Trim(ref, "${}")]; ok {
+				return v
+			}
+			return ref
+		})
+		if val["version"] != declared {
+			val["raw_version"] = declared
+		}
+		return val
+	}
+
+	// dependency notations are only looked for in dependencies blocks (including those nested in buildscript,
+	// allprojects and the like), and plugin ids in plugins blocks, as they are ambiguous anywhere else.
+	var dependencies, plugins = blocks(script, dependenciesBlock), blocks(script, pluginsBlock)
+
+	for _, match := range stringNotation.FindAllStringSubmatch(dependencies, -1) {
+		if notConfigurations[match[1]] {
+			continue
+		}
+
+		var val = map[string]any{"configuration": match[1], "group": match[3], "name": match[4], "platform": match[2] != ""}
+		if match[6] != "" {
+			val["classifier"] = match[6]
+		}
+		if match[7] != "" {
+			val["extension"] = match[7]
+		}
+		emit("@java/gradle/dependency", versioned(val, match[5]))
+	}
+
+	for _, match := range mapNotation.FindAllStringSubmatch(dependencies, -1) {
+		var val = map[string]any{"configuration": match[1], "group": match[2], "name": match[3], "platform": false}
+		emit("@java/gradle/dependency", versioned(val, match[4]))
+	}
+
+	for _, match := range projectNotation.FindAllStringSubmatch(dependencies, -1) {
+		emit("@java/gradle/dependency", map[string]any{"configuration": match[1], "project": match[2], "platform": false})
+	}
+
+	for _, match := range catalogNotation.FindAllStringSubmatch(dependencies, -1) {
+		if notConfigurations[match[1]] || strings.HasPrefix(match[3], "libs.plugins.") || strings.HasPrefix(match[3], "libs.versions.") {
+			continue
+		}
+
+		// references point to either a single library (libs.x) or a bundle of libraries (libs.bundles.x)
+		var val = map[string]any{"configuration": match[1], "catalog_ref": match[3], "platform": match[2] != ""}
+		emit("@java/gradle/dependency", val)
+	}
+
+	// the legacy apply plugin: 'x' syntax is used outside of any block, and is unambiguous
+	for _, re := range []*regexp.Regexp{pluginID, pluginKotlin, pluginApply} {
+		var source = plugins
+		if re == pluginApply {
+			source = script
+		}
+		for _, match := range re.FindAllStringSubmatch(source, -1) {
+			var id = match[1]
+			if re == pluginKotlin {
+				id = "org.jetbrains.kotlin." + id // kotlin("jvm") is a shorthand for id("org.jetbrains.kotlin.jvm")
+			}
+
+			var val = map[string]any{"id": id, "version": ""}
+			if len(match) > 2 {
+				val = versioned(val, match[2])
+			}
+			emit("@java/gradle/plugin", val)
+		}
+	}
+
+	for _, match := range pluginAlias.FindAllStringSubmatch(plugins, -1) {
+		emit("@java/gradle/plugin", versioned(map[string]any{"catalog_ref": match[1]}, match[2]))
+	}
+
+	return facts, nil
+}
+
+// blocks returns the bodies of all the blocks opened by re in script, joined by newlines. Blocks nested in an
+// earlier match are part of its body, and are not repeated.
+func blocks(script string, re *regexp.Regexp) string {
+	var bodies []string
+	var end = 0
+	for _, loc := range re.FindAllStringIndex(script, -1) {
+		if loc[0] < end {
+			continue
+		}
+
+		// find the matching closing brace; braces in strings (as in "${version}") are balanced, so simple counting suffices
+		var depth = 1
+		for end = loc[1]; end < len(script) && depth > 0; end++ {
+			switch script[end] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+			}
+		}
+		bodies = append(bodies, script[loc[1]:end])
+	}
+	return strings.Join(bodies, "\n")
+}
+
+// register the BuildScanner with scanner registry
+func init() { scanner.Register("java/gradle/build", &BuildScanner{}) }
@@ -0,0 +1,77 @@
+package gradle
+
+import (
+	"context"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"github.com/mergestat/kyc/pkg/scanner/scannertest"
+	"reflect"
+	"testing"
+)
+
+func TestBuildScanner_Scan(t *testing.T) {
+	var cases = []struct {
+		name   string
+		script string
+		want   []scanner.Fact
+	}{
+		{
+			name: "repositories",
+			script: `
+repositories {
+    mavenCentral()
+    maven("https://jitpack.io")
+    maven { url = uri("https://repo.spring.io/milestone") }
+}
+
+dependencies {
+    implementation("com.google.guava:guava:32.0.1-jre")
+}
+`,
+			want: []scanner.Fact{
+				{Key: "@java/gradle/dependency", Value: map[string]any{"configuration": "implementation", "group": "com.google.guava", "name": "guava", "version": "32.0.1-jre", "platform": false}},
+			},
+		},
+		{
+			name: "kotlin dependency",
+			script: `
+plugins {
+    kotlin("jvm") version "1.9.0"
+}
+
+dependencies {
+    implementation(kotlin("stdlib"))
+    testImplementation(kotlin("test"))
+}
+`,
+			want: []scanner.Fact{
+				{Key: "@java/gradle/plugin", Value: map[string]any{"id": "org.jetbrains.kotlin.jvm", "version": "1.9.0"}},
+			},
+		},
+		{
+			name: "apply plugin",
+			script: `
+apply plugin: 'java'
+
+dependencies {
+    compileOnly 'org.projectlombok:lombok:1.18.28'
+}
+`,
+			want: []scanner.Fact{
+				{Key: "@java/gradle/dependency", Value: map[string]any{"configuration": "compileOnly", "group": "org.projectlombok", "name": "lombok", "version": "1.18.28", "platform": false}},
+				{Key: "@java/gradle/plugin", Value: map[string]any{"id": "java", "version": ""}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got, err = (&BuildScanner{}).Scan(context.Background(), scannertest.File("build.gradle.kts", tc.script))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
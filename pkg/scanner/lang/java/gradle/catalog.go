@@ -0,0 +1,153 @@
+package gradle
+
+import (
+	"context"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Catalog represents a gradle version catalog (https://docs.gradle.org/current/userguide/platforms.html).
+// Entries support both a short (string) and a long (table) syntax, and are decoded as-is.
+type Catalog struct {
+	Versions  map[string]any      `toml:"versions"`
+	Libraries map[string]any      `toml:"libraries"`
+	Plugins   map[string]any      `toml:"plugins"`
+	Bundles   map[string][]string `toml:"bundles"`
+}
+
+// CatalogScanner implements scanner.Scanner to extract facts from gradle version catalogs, such as gradle/libs.versions.toml
+type CatalogScanner struct{}
+
+func (c *CatalogScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && strings.HasSuffix(path.Base(file.Name), ".versions.toml")
+}
+
+func (c *CatalogScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var catalog Catalog
+	if _, err = toml.NewDecoder(reader).Decode(&catalog); err != nil {
+		return nil, err
+	}
+
+	// the catalog is exposed to build scripts under an accessor named after the file, as in libs for libs.versions.toml;
+	// accessors are emitted to allow joining with the catalog_ref of @java/gradle/{dependency,plugin} facts.
+	var name = strings.TrimSuffix(path.Base(file.Name), ".versions.toml")
+	var accessor = func(prefix, alias string) string {
+		return name + "." + prefix + aliasSeparators.ReplaceAllString(alias, ".")
+	}
+
+	// version returns the version of an entry, either declared inline or as a reference to the [versions] table
+	var version = func(val map[string]any, v any) map[string]any {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["ref"].(string); ok {
+				val["version_ref"], val["version"] = ref, versionString(catalog.Versions[ref])
+			} else {
+				val["version"] = versionString(v)
+			}
+		default:
+			val["version"] = versionString(v)
+		}
+		return val
+	}
+
+	for _, alias := range sortedKeys(catalog.Versions) {
+		emit("@java/gradle/catalog-version", map[string]any{"alias": alias, "version": versionString(catalog.Versions[alias])})
+	}
+
+	for _, alias := range sortedKeys(catalog.Libraries) {
+		var val = map[string]any{"alias": alias, "accessor": accessor("", alias)}
+
+		switch lib := catalog.Libraries[alias].(type) {
+		case string: // group:name:version
+			var parts = strings.SplitN(lib, ":", 3)
+			for len(parts) < 3 {
+				parts = append(parts, "")
+			}
+			val["group"], val["name"], val["version"] = parts[0], parts[1], parts[2]
+		case map[string]any:
+			var module, _ = lib["module"].(string)
+			var group, name, _ = strings.Cut(module, ":")
+			if group == "" {
+				group, _ = lib["group"].(string)
+				name, _ = lib["name"].(string)
+			}
+			val["group"], val["name"] = group, name
+			version(val, lib["version"])
+		}
+
+		emit("@java/gradle/catalog-library", val)
+	}
+
+	for _, alias := range sortedKeys(catalog.Plugins) {
+		var val = map[string]any{"alias": alias, "accessor": accessor("plugins.", alias)}
+
+		switch plugin := catalog.Plugins[alias].(type) {
+		case string: // id:version
+			var id, v, _ = strings.Cut(plugin, ":")
+			val["id"], val["version"] = id, v
+		case map[string]any:
+			val["id"] = plugin["id"]
+			version(val, plugin["version"])
+		}
+
+		emit("@java/gradle/catalog-plugin", val)
+	}
+
+	for _, alias := range sortedKeys(catalog.Bundles) {
+		emit("@java/gradle/catalog-bundle", map[string]any{
+			"alias": alias, "accessor": accessor("bundles.", alias), "libraries": catalog.Bundles[alias],
+		})
+	}
+
+	return facts, nil
+}
+
+// separators in aliases are turned into dots in accessors, as in spring-boot-starter to libs.spring.boot.starter
+var aliasSeparators = regexp.MustCompile(`[-_.]`)
+
+// versionString formats a version, declared either as a string, or as a rich version table (such as { strictly = "[1.0, 2.0[", prefer = "1.5" })
+func versionString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any:
+		for _, key := range []string{"strictly", "require", "prefer"} {
+			if s, ok := v[key].(string); ok {
+				return s
+			}
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// sortedKeys returns the keys of the map in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	var keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// register the CatalogScanner with scanner registry
+func init() { scanner.Register("java/gradle/catalog", &CatalogScanner{}) }
@@ -0,0 +1,220 @@
+package maven
+
+import (
+	"context"
+	"encoding/xml"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Dependency represents a dependency, as declared in the dependencies (or dependencyManagement) section of a pom
+type Dependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+	Type       string `xml:"type"`
+	Classifier string `xml:"classifier"`
+	Optional   string `xml:"optional"`
+}
+
+// Plugin represents a build plugin, as declared in the plugins (or pluginManagement) section of a pom
+type Plugin struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+// Properties collects the user-defined properties of a pom, which are declared as arbitrary elements
+type Properties map[string]string
+
+func (p *Properties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	*p = make(Properties)
+	for {
+		var token, err = d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			var value string
+			if err = d.DecodeElement(&value, &el); err != nil {
+				return err
+			}
+			(*p)[el.Name.Local] = strings.TrimSpace(value)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// Project represents the subset of a pom (https://maven.apache.org/pom.html) we are interested in.
+type Project struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Packaging  string `xml:"packaging"`
+
+	Parent struct {
+		GroupID      string `xml:"groupId"`
+		ArtifactID   string `xml:"artifactId"`
+		Version      string `xml:"version"`
+		RelativePath string `xml:"relativePath"`
+	} `xml:"parent"`
+
+	Properties Properties `xml:"properties"`
+
+	Dependencies         []Dependency `xml:"dependencies>dependency"`
+	DependencyManagement []Dependency `xml:"dependencyManagement>dependencies>dependency"`
+
+	Plugins          []Plugin `xml:"build>plugins>plugin"`
+	PluginManagement []Plugin `xml:"build>pluginManagement>plugins>plugin"`
+}
+
+// PomScanner implements scanner.Scanner to extract facts from maven pom.xml files.
+type PomScanner struct{}
+
+func (p *PomScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == "pom.xml"
+}
+
+func (p *PomScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var project Project
+	if err = xml.NewDecoder(reader).Decode(&project); err != nil {
+		return nil, err
+	}
+
+	// the group and version are inherited from the parent, if not declared
+	var groupID, version = project.GroupID, project.Version
+	if groupID == "" {
+		groupID = project.Parent.GroupID
+	}
+	if version == "" {
+		version = project.Parent.Version
+	}
+
+	var packaging = project.Packaging
+	if packaging == "" {
+		packaging = "jar"
+	}
+
+	var resolve = resolver(&project, groupID, version)
+
+	// versioned adds the resolved version to the fact value, keeping the declared one if it referenced any properties
+	var versioned = func(val map[string]any, declared string) map[string]any {
+		val["version"] = resolve(declared)
+		if val["version"] != declared {
+			val["raw_version"] = declared
+		}
+		return val
+	}
+
+	emit("@java/maven/project", versioned(map[string]any{
+		"group_id": resolve(groupID), "artifact_id": project.ArtifactID, "packaging": packaging,
+	}, version))
+
+	if parent := project.Parent; parent.ArtifactID != "" {
+		var relativePath = parent.RelativePath
+		if relativePath == "" {
+			relativePath = "../pom.xml"
+		}
+		emit("@java/maven/parent", map[string]any{
+			"group_id": parent.GroupID, "artifact_id": parent.ArtifactID, "version": parent.Version, "relative_path": relativePath,
+		})
+	}
+
+	var dependencies = func(deps []Dependency, managed bool) {
+		for _, dep := range deps {
+			// managed dependencies only set defaults, and so have no default scope or type of their own
+			var scope, typ = dep.Scope, dep.Type
+			if scope == "" && !managed {
+				scope = "compile"
+			}
+			if typ == "" && !managed {
+				typ = "jar"
+			}
+
+			emit("@java/maven/dependency", versioned(map[string]any{
+				"group_id": resolve(dep.GroupID), "artifact_id": resolve(dep.ArtifactID), "scope": scope, "type": typ,
+				"classifier": resolve(dep.Classifier), "optional": dep.Optional == "true", "managed": managed,
+			}, dep.Version))
+		}
+	}
+
+	dependencies(project.Dependencies, false)
+	dependencies(project.DependencyManagement, true)
+
+	var plugins = func(plugins []Plugin, managed bool) {
+		for _, plugin := range plugins {
+			var groupID = plugin.GroupID
+			if groupID == "" {
+				groupID = "org.apache.maven.plugins" // the default group for plugins
+			}
+
+			emit("@java/maven/plugin", versioned(map[string]any{
+				"group_id": resolve(groupID), "artifact_id": resolve(plugin.ArtifactID), "managed": managed,
+			}, plugin.Version))
+		}
+	}
+
+	plugins(project.Plugins, false)
+	plugins(project.PluginManagement, true)
+
+	return facts, nil
+}
+
+// matches property references, as in ${spring.version}
+var propertyRef = regexp.MustCompile(`\$\{([^}]+)}`)
+
+// resolver returns a function that substitutes references to properties declared in the pom, as well as to
+// the built-in project.* properties. References to properties that are not known (such as the ones inherited
+// from a parent pom, or passed on the command-line) are left as-is.
+func resolver(project *Project, groupID, version string) func(string) string {
+	var builtin = map[string]string{
+		"groupId": groupID, "artifactId": project.ArtifactID, "version": version,
+		"parent.groupId": project.Parent.GroupID, "parent.version": project.Parent.Version,
+	}
+
+	var properties = make(map[string]string)
+	for key, val := range builtin {
+		properties["project."+key], properties["pom."+key] = val, val // pom.* are deprecated aliases
+	}
+	for key, val := range project.Properties {
+		properties[key] = val
+	}
+
+	return func(str string) string {
+		// properties may refer to other properties; the depth is capped to guard against cycles
+		for i := 0; i < 10 && strings.Contains(str, "${"); i++ {
+			var next = propertyRef.ReplaceAllStringFunc(str, func(ref string) string {
+				if val, ok := properties[ref[2:len(ref)-1]]; ok {
+					return val
+				}
+				return ref
+			})
+			if next == str {
+				break
+			}
+			str = next
+		}
+		return str
+	}
+}
+
+// register the PomScanner with scanner registry
+func init() { scanner.Register("java/maven/pom", &PomScanner{}) }
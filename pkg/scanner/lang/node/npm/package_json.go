@@ -3,28 +3,92 @@ package npm
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/mergestat/kyc/pkg/scanner"
 	"io"
+	"path"
+	"sort"
 	"strings"
 )
 
+// PackageJson represents the subset of a package.json (https://docs.npmjs.com/cli/configuring-npm/package-json) we are interested in.
+// Fields that support more than one syntax (such as bin, exports or workspaces) are decoded as-is, and maps of strings
+// tolerate other (legacy or malformed) shapes, so that a single odd field doesn't fail the whole manifest.
 type PackageJson struct {
-	LockfileVersion string `json:"lockfileVersion"`
+	Name           string    `json:"name"`
+	Version        string    `json:"version"`
+	Private        bool      `json:"private"`
+	License        any       `json:"license"`
+	Engines        StringMap `json:"engines"`
+	PackageManager string    `json:"packageManager"`
+	Scripts        StringMap `json:"scripts"`
+	Workspaces     any       `json:"workspaces"`
 
-	Dependencies     map[string]string `json:"dependencies"`
-	DevDependencies  map[string]string `json:"devDependencies"`
-	PeerDependencies map[string]string `json:"peerDependencies"`
+	Bin     any    `json:"bin"`
+	Main    string `json:"main"`
+	Module  string `json:"module"`
+	Exports any    `json:"exports"`
+
+	Dependencies         StringMap `json:"dependencies"`
+	DevDependencies      StringMap `json:"devDependencies"`
+	PeerDependencies     StringMap `json:"peerDependencies"`
+	OptionalDependencies StringMap `json:"optionalDependencies"`
+	BundledDependencies  any       `json:"bundledDependencies"`
+	BundleDependencies   any       `json:"bundleDependencies"` // alias of bundledDependencies
+
+	Overrides   any       `json:"overrides"`   // npm
+	Resolutions StringMap `json:"resolutions"` // yarn
+}
+
+// StringMap is a map of strings that decodes any other json value (such as the legacy list form of engines) as empty,
+// and skips entries whose value is not a string.
+type StringMap map[string]string
+
+func (m *StringMap) UnmarshalJSON(data []byte) error {
+	var val any
+	if err := json.Unmarshal(data, &val); err != nil {
+		return err
+	}
+
+	if obj, ok := val.(map[string]any); ok {
+		*m = make(StringMap, len(obj))
+		for key, v := range obj {
+			if s, ok := v.(string); ok {
+				(*m)[key] = s
+			}
+		}
+	}
+	return nil
+}
+
+// WorkspacePatterns returns the workspace globs, declared either as a list or (by yarn) as an object with a packages list
+func (p *PackageJson) WorkspacePatterns() []string {
+	switch workspaces := p.Workspaces.(type) {
+	case []any:
+		return strs(workspaces)
+	case map[string]any:
+		if packages, ok := workspaces["packages"].([]any); ok {
+			return strs(packages)
+		}
+	}
+	return nil
 }
 
-type PackageJsonScanner struct{}
+// PackageJsonScanner implements scanner.Scanner to extract facts from package.json manifests.
+type PackageJsonScanner struct {
+	// SkipNodeModules skips manifests of packages installed (or vendored) under node_modules/
+	SkipNodeModules bool
+}
 
 func (p *PackageJsonScanner) Supports(file *object.File) bool {
-	return file.Mode.IsFile() && strings.HasSuffix(file.Name, "package.json")
+	return file.Mode.IsFile() && path.Base(file.Name) == "package.json" && !(p.SkipNodeModules && inNodeModules(file.Name))
 }
 
-func (p *PackageJsonScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+func (p *PackageJsonScanner) Scan(ctx context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
 	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
 
 	// read the file content to parse
 	var reader io.ReadCloser
@@ -38,7 +102,69 @@ func (p *PackageJsonScanner) Scan(_ context.Context, file *object.File, _ ...str
 		return nil, err
 	}
 
-	// for each {dependency, devDependency, peerDependency}, emit a fact
+	var pkg = map[string]any{"name": packageJson.Name, "version": packageJson.Version, "private": packageJson.Private}
+	switch license := packageJson.License.(type) {
+	case string:
+		pkg["license"] = license
+	case map[string]any: // deprecated object syntax, as in { "type": "MIT", "url": "..." }
+		pkg["license"] = license["type"]
+	}
+	emit("@node/npm/package", pkg)
+
+	for name, version := range packageJson.Engines {
+		emit("@node/npm/engine", map[string]any{"name": name, "version": version})
+	}
+
+	// packageManager is of the form <name>@<version>[+<hash>], as used by corepack
+	if packageJson.PackageManager != "" {
+		var val = map[string]any{"spec": packageJson.PackageManager}
+		var name, version, _ = strings.Cut(packageJson.PackageManager, "@")
+		val["name"], val["version"] = name, version
+		if version, hash, found := strings.Cut(version, "+"); found {
+			val["version"], val["hash"] = version, hash
+		}
+		emit("@node/npm/package-manager", val)
+	}
+
+	for name, command := range packageJson.Scripts {
+		emit("@node/npm/script", map[string]any{"name": name, "command": command})
+	}
+
+	for _, pattern := range packageJson.WorkspacePatterns() {
+		emit("@node/npm/workspace", map[string]any{"pattern": pattern})
+	}
+
+	// record whether the manifest is the root of a workspace, and / or a member of one declared by a parent manifest
+	if len(packageJson.WorkspacePatterns()) > 0 {
+		emit("@node/npm/workspace-role", map[string]any{"role": "root"})
+	}
+	if tree, ok := scanner.TreeFromContext(ctx); ok {
+		if root, pattern, found := workspaceRoot(ctx, tree, path.Dir(file.Name)); found {
+			emit("@node/npm/workspace-role", map[string]any{"role": "member", "root": root, "pattern": pattern})
+		}
+	}
+
+	switch bin := packageJson.Bin.(type) {
+	case string: // a single executable, named after the package (without its scope)
+		emit("@node/npm/bin", map[string]any{"name": path.Base(packageJson.Name), "path": bin})
+	case map[string]any:
+		for name, bin := range bin {
+			emit("@node/npm/bin", map[string]any{"name": name, "path": fmt.Sprint(bin)})
+		}
+	}
+
+	if packageJson.Main != "" {
+		emit("@node/npm/entrypoint", map[string]any{"field": "main", "path": packageJson.Main})
+	}
+	if packageJson.Module != "" {
+		emit("@node/npm/entrypoint", map[string]any{"field": "module", "path": packageJson.Module})
+	}
+	for _, export := range exports(packageJson.Exports, ".", nil) {
+		export["field"] = "exports"
+		emit("@node/npm/entrypoint", export)
+	}
+
+	// for each {dependency, devDependency, peerDependency, optionalDependency}, emit a fact
 	for name, version := range packageJson.Dependencies {
 		var val = map[string]any{"name": name, "version": version}
 		facts = append(facts, scanner.Fact{Key: "@node/npm/dependency", Value: val})
@@ -51,9 +177,179 @@ func (p *PackageJsonScanner) Scan(_ context.Context, file *object.File, _ ...str
 		var val = map[string]any{"name": name, "version": version, "peer": true}
 		facts = append(facts, scanner.Fact{Key: "@node/npm/dependency", Value: val})
 	}
+	for name, version := range packageJson.OptionalDependencies {
+		var val = map[string]any{"name": name, "version": version, "optional": true}
+		facts = append(facts, scanner.Fact{Key: "@node/npm/dependency", Value: val})
+	}
+
+	// bundled dependencies are either a list of names, or true to bundle all (non-dev) dependencies
+	var bundled = packageJson.BundledDependencies
+	if bundled == nil {
+		bundled = packageJson.BundleDependencies
+	}
+	var bundledNames []string
+	switch bundled := bundled.(type) {
+	case []any:
+		bundledNames = strs(bundled)
+	case bool:
+		if bundled {
+			for name := range packageJson.Dependencies {
+				bundledNames = append(bundledNames, name)
+			}
+		}
+	}
+	for _, name := range bundledNames {
+		emit("@node/npm/bundled-dependency", map[string]any{"name": name})
+	}
+
+	// overrides may be nested, to only apply to dependencies of a given package; these are flattened
+	// into a single path, with a " > " separator (the same one used by npm when describing dependency chains).
+	var npmOverrides, _ = packageJson.Overrides.(map[string]any)
+	for _, override := range overrides(npmOverrides, nil) {
+		override["source"] = "overrides"
+		emit("@node/npm/override", override)
+	}
+	for pattern, version := range packageJson.Resolutions {
+		emit("@node/npm/override", map[string]any{"path": pattern, "version": version, "source": "resolutions"})
+	}
 
 	return facts, nil
 }
 
+// workspaceRoot looks for a package.json in the ancestors of dir whose workspaces include dir,
+// returning the directory of the workspace root along with the matching pattern.
+func workspaceRoot(ctx context.Context, tree *object.Tree, dir string) (root, pattern string, found bool) {
+	for root = dir; root != "."; {
+		root = path.Dir(root)
+
+		if pattern, found = workspaceMatch(workspacePatterns(ctx, tree, root), relative(root, dir)); found {
+			return root, pattern, true
+		}
+	}
+
+	return "", "", false
+}
+
+// workspacesKey is the key under which the workspace patterns of the manifest in dir are cached
+type workspacesKey struct{ dir string }
+
+// workspacePatterns returns the workspace patterns declared by the package.json in dir, if any. As every manifest
+// in a monorepo looks up the same ancestors, the result is cached for the tree (see scanner.Cached).
+func workspacePatterns(ctx context.Context, tree *object.Tree, dir string) []string {
+	return scanner.Cached(ctx, workspacesKey{dir}, func() any {
+		var file, err = tree.File(path.Join(dir, "package.json"))
+		if err != nil {
+			return []string(nil) // no manifest at this level
+		}
+
+		var content string
+		if content, err = file.Contents(); err != nil {
+			return []string(nil)
+		}
+
+		var parent PackageJson
+		if err = json.Unmarshal([]byte(content), &parent); err != nil {
+			return []string(nil)
+		}
+		return parent.WorkspacePatterns()
+	}).([]string)
+}
+
+// workspaceMatch returns the workspace pattern matching dir, taking into account negated (!) patterns
+func workspaceMatch(patterns []string, dir string) (matched string, found bool) {
+	for _, pattern := range patterns {
+		var negated = strings.HasPrefix(pattern, "!")
+		var glob = path.Clean(strings.TrimPrefix(pattern, "!"))
+		if ok, _ := doublestar.Match(glob, dir); ok {
+			matched, found = pattern, !negated // later patterns take precedence over earlier ones
+		}
+	}
+	return matched, found
+}
+
+// relative returns the path of target relative to base, where base is an ancestor of target (or ".")
+func relative(base, target string) string {
+	if base == "." {
+		return target
+	}
+	return strings.TrimPrefix(target, base+"/")
+}
+
+// inNodeModules reports whether the named file lives under a node_modules/ directory
+func inNodeModules(name string) bool {
+	return strings.HasPrefix(name, "node_modules/") || strings.Contains(name, "/node_modules/")
+}
+
+// exports flattens the exports field (https://nodejs.org/api/packages.html#exports) into a list of subpath / condition / path
+// entries. Keys starting with a dot are subpaths, and all other keys are (possibly nested) conditions, such as import or require.
+func exports(val any, subpath string, conditions []string) []map[string]any {
+	switch val := val.(type) {
+	case string:
+		var export = map[string]any{"subpath": subpath, "path": val}
+		if len(conditions) > 0 {
+			export["condition"] = strings.Join(conditions, "+")
+		}
+		return []map[string]any{export}
+	case []any: // fallbacks, tried in order
+		var result []map[string]any
+		for _, v := range val {
+			result = append(result, exports(v, subpath, conditions)...)
+		}
+		return result
+	case map[string]any:
+		var result []map[string]any
+		for _, key := range sortedKeys(val) {
+			if strings.HasPrefix(key, ".") {
+				result = append(result, exports(val[key], key, conditions)...)
+			} else {
+				result = append(result, exports(val[key], subpath, append(conditions[:len(conditions):len(conditions)], key))...)
+			}
+		}
+		return result
+	}
+	return nil // null excludes the subpath
+}
+
+// overrides flattens the (possibly nested) overrides field into a list of path / version entries
+func overrides(val map[string]any, parents []string) []map[string]any {
+	var result []map[string]any
+	for _, name := range sortedKeys(val) {
+		if name == "." { // the override for the parent package itself
+			result = append(result, map[string]any{"path": strings.Join(parents, " > "), "version": fmt.Sprint(val[name])})
+			continue
+		}
+
+		var current = append(parents[:len(parents):len(parents)], name)
+		switch v := val[name].(type) {
+		case map[string]any:
+			result = append(result, overrides(v, current)...)
+		default:
+			result = append(result, map[string]any{"path": strings.Join(current, " > "), "version": fmt.Sprint(v)})
+		}
+	}
+	return result
+}
+
+// sortedKeys returns the keys of the map in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	var keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// strs returns the string elements of the list, skipping all others
+func strs(list []any) []string {
+	var result []string
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
 // register the PackageJsonScanner with scanner registry
-func init() { scanner.Register("node/npm/package-json", &PackageJsonScanner{}) }
+func init() { scanner.Register("node/npm/package-json", &PackageJsonScanner{SkipNodeModules: true}) }
@@ -6,27 +6,85 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/mergestat/kyc/pkg/scanner"
 	"io"
+	"path"
 	"strings"
 )
 
+// LockedPackage represents a single package in a lockfile; it is used both for entries of the packages map (in lockfile v2 and v3)
+// and for (nested) entries of the dependencies map (in lockfile v1).
+type LockedPackage struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Resolved  string `json:"resolved"`
+	Integrity string `json:"integrity"`
+	Link      bool   `json:"link"`
+
+	Dev         bool `json:"dev"`
+	Optional    bool `json:"optional"`
+	DevOptional bool `json:"devOptional"`
+	Peer        bool `json:"peer"`
+
+	// declared dependencies of the root project and workspace members (v2 and v3 only)
+	Dependencies         map[string]string `json:"-"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+
+	// required and nested dependencies (v1 only)
+	Requires map[string]string        `json:"requires"`
+	Nested   map[string]LockedPackage `json:"-"`
+}
+
+// UnmarshalJSON decodes the dependencies field, which holds declared version ranges in v2 / v3 but nested packages in v1.
+func (p *LockedPackage) UnmarshalJSON(data []byte) (err error) {
+	type plain LockedPackage
+	var raw struct {
+		*plain
+		Dependencies json.RawMessage `json:"dependencies"`
+	}
+	raw.plain = (*plain)(p)
+	if err = json.Unmarshal(data, &raw); err != nil || len(raw.Dependencies) == 0 {
+		return err
+	}
+
+	// try the v2 / v3 format first, falling back to v1 nested packages
+	if err = json.Unmarshal(raw.Dependencies, &p.Dependencies); err != nil {
+		p.Dependencies = nil
+		return json.Unmarshal(raw.Dependencies, &p.Nested)
+	}
+	return nil
+}
+
+// declares returns true if the package declares name in any of its dependency maps
+func (p *LockedPackage) declares(name string) bool {
+	for _, deps := range []map[string]string{p.Dependencies, p.DevDependencies, p.PeerDependencies, p.OptionalDependencies} {
+		if _, ok := deps[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 type PackageLock struct {
-	Name    string `json:"name"`
-	Version string `json:"version"`
+	Name            string `json:"name"`
+	Version         string `json:"version"`
+	LockfileVersion int    `json:"lockfileVersion"`
 
-	Packages map[string]struct {
-		Version   string `json:"version"`
-		Resolved  string `json:"resolved"`
-		Integrity string `json:"integrity"`
-	} `json:"packages"`
+	Packages     map[string]LockedPackage `json:"packages"`     // v2 and v3
+	Dependencies map[string]LockedPackage `json:"dependencies"` // v1 (and v2, for backwards compatibility)
 }
 
-type PackageLockScanner struct{}
+// PackageLockScanner implements scanner.Scanner to extract facts from package-lock.json files.
+type PackageLockScanner struct {
+	// SkipNodeModules skips lockfiles of packages installed (or vendored) under node_modules/
+	SkipNodeModules bool
+}
 
 func (p *PackageLockScanner) Supports(file *object.File) bool {
-	return file.Mode.IsFile() && strings.HasSuffix(file.Name, "package-lock.json")
+	return file.Mode.IsFile() && path.Base(file.Name) == "package-lock.json" && !(p.SkipNodeModules && inNodeModules(file.Name))
 }
 
-func (p *PackageLockScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+func (p *PackageLockScanner) Scan(ctx context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
 	var facts []scanner.Fact
 
 	// read the file content to parse
@@ -41,13 +99,139 @@ func (p *PackageLockScanner) Scan(_ context.Context, file *object.File, _ ...str
 		return nil, err
 	}
 
-	for name, pkg := range packageLock.Packages {
-		var val = map[string]any{"path": name, "version": pkg.Version, "resolved": pkg.Resolved, "integrity": pkg.Integrity}
+	var emit = func(pth, name string, pkg LockedPackage, direct bool) {
+		var val = map[string]any{
+			"path": pth, "name": name, "version": pkg.Version, "resolved": pkg.Resolved, "integrity": pkg.Integrity,
+			"dev": pkg.Dev, "optional": pkg.Optional, "peer": pkg.Peer,
+			"depth": strings.Count(pth, "node_modules/"), "direct": direct,
+		}
+		if pkg.DevOptional {
+			val["dev_optional"] = true // optional dependency of a dev dependency (or vice versa)
+		}
+		if pkg.Link {
+			val["link"] = true // symlink to a local package (such as a workspace member) rather than an installed one
+		}
 		facts = append(facts, scanner.Fact{Key: "@node/npm/dependency-locked", Value: val})
 	}
 
+	// lockfile v2 and v3 (npm 7+) list every package in a flat map keyed by its install path,
+	// with the root project under "" and workspace members under their (non node_modules/) directory
+	if len(packageLock.Packages) > 0 {
+		var projects []LockedPackage // the root project and workspace members, which declare direct dependencies
+		for pth, pkg := range packageLock.Packages {
+			if !strings.Contains(pth, "node_modules/") {
+				projects = append(projects, pkg)
+			}
+		}
+
+		for pth, pkg := range packageLock.Packages {
+			if !strings.Contains(pth, "node_modules/") {
+				continue // the root project and workspace members are not dependencies themselves
+			}
+
+			var name = packageName(pth)
+
+			// packages installed directly under a project's node_modules/ are direct dependencies, if any project declares them;
+			// due to hoisting, dependencies of workspace members end up in the root node_modules/ as well.
+			var direct = false
+			if strings.Count(pth, "node_modules/") == 1 {
+				for i := range projects {
+					direct = direct || projects[i].declares(name)
+				}
+			}
+
+			emit(pth, name, pkg, direct)
+		}
+
+		return facts, nil
+	}
+
+	// lockfile v1 (npm 5 and 6) nests packages under the dependencies of the package they are installed under
+	var declared = rootDeclared(ctx, path.Dir(file.Name))
+	if declared == nil {
+		// without the sibling package.json, top-level packages not required by any other package are assumed to be direct
+		declared = make(map[string]bool)
+		for name := range packageLock.Dependencies {
+			declared[name] = true
+		}
+
+		// a require only accounts for the top-level package if it resolves to it, as node does: looking first in the
+		// requiring package's own nested dependencies, then in each enclosing scope up to the top-level one.
+		// scopes lists the enclosing scopes of the packages in scopes[0], innermost first.
+		var unmark func(scopes []map[string]LockedPackage)
+		unmark = func(scopes []map[string]LockedPackage) {
+			for _, pkg := range scopes[0] {
+				var chain = append([]map[string]LockedPackage{pkg.Nested}, scopes...)
+				for name := range pkg.Requires {
+					for i, scope := range chain {
+						if _, ok := scope[name]; ok {
+							if i == len(chain)-1 {
+								delete(declared, name)
+							}
+							break
+						}
+					}
+				}
+				if len(pkg.Nested) > 0 {
+					unmark(chain)
+				}
+			}
+		}
+		unmark([]map[string]LockedPackage{packageLock.Dependencies})
+	}
+
+	var walk func(parent string, deps map[string]LockedPackage)
+	walk = func(parent string, deps map[string]LockedPackage) {
+		for _, name := range sortedKeys(deps) {
+			var pkg = deps[name]
+			var pth = parent + "node_modules/" + name
+			emit(pth, name, pkg, parent == "" && declared[name])
+			walk(pth+"/", pkg.Nested)
+		}
+	}
+	walk("", packageLock.Dependencies)
+
 	return facts, nil
 }
 
+// packageName derives the name of a package from its install path, as in node_modules/a/node_modules/@scope/b
+func packageName(pth string) string {
+	var i = strings.LastIndex(pth, "node_modules/")
+	return pth[i+len("node_modules/"):]
+}
+
+// rootDeclared returns the set of dependencies declared in the package.json in dir, or nil if it is not available
+func rootDeclared(ctx context.Context, dir string) map[string]bool {
+	var tree, ok = scanner.TreeFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	var file, err = tree.File(path.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+
+	var content string
+	if content, err = file.Contents(); err != nil {
+		return nil
+	}
+
+	var packageJson PackageJson
+	if err = json.Unmarshal([]byte(content), &packageJson); err != nil {
+		return nil
+	}
+
+	var declared = make(map[string]bool)
+	for _, deps := range []map[string]string{
+		packageJson.Dependencies, packageJson.DevDependencies, packageJson.PeerDependencies, packageJson.OptionalDependencies,
+	} {
+		for name := range deps {
+			declared[name] = true
+		}
+	}
+	return declared
+}
+
 // register the PackageLockScanner with scanner registry
-func init() { scanner.Register("node/npm/package-lock", &PackageLockScanner{}) }
+func init() { scanner.Register("node/npm/package-lock", &PackageLockScanner{SkipNodeModules: true}) }
@@ -0,0 +1,190 @@
+package pnpm
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Importer represents a project in the workspace (or the only project, for lockfiles without importers).
+type Importer struct {
+	Specifiers           map[string]string     `yaml:"specifiers"` // v5 only
+	Dependencies         map[string]Dependency `yaml:"dependencies"`
+	DevDependencies      map[string]Dependency `yaml:"devDependencies"`
+	OptionalDependencies map[string]Dependency `yaml:"optionalDependencies"`
+}
+
+// Dependency represents a dependency of an importer, recorded as either a resolved version (in v5),
+// or as a specifier / version pair (in v6 and v9).
+type Dependency struct {
+	Specifier string `yaml:"specifier"`
+	Version   string `yaml:"version"`
+}
+
+func (d *Dependency) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		d.Version = node.Value
+		return nil
+	}
+
+	type plain Dependency // to not recurse into UnmarshalYAML
+	return node.Decode((*plain)(d))
+}
+
+// Package represents an entry of the packages (or, in v9, snapshots) section.
+type Package struct {
+	Name       string `yaml:"name"`    // only set for packages not resolved from the registry
+	Version    string `yaml:"version"` // only set for packages not resolved from the registry
+	Resolution struct {
+		Integrity string `yaml:"integrity"`
+		Tarball   string `yaml:"tarball"`
+	} `yaml:"resolution"`
+	Dev      *bool `yaml:"dev"` // not recorded since v9
+	Optional bool  `yaml:"optional"`
+}
+
+// PnpmLock represents the subset of pnpm-lock.yaml we are interested in, across lockfile versions 5.x, 6.x and 9.x.
+// It is decoded with yaml.v3, which retains the original text of scalars (so that an unquoted 1.10 isn't read as 1.1).
+type PnpmLock struct {
+	LockfileVersion string `yaml:"lockfileVersion"`
+
+	Importer  `yaml:",inline"`    // for single project lockfiles, the root project is inlined
+	Importers map[string]Importer `yaml:"importers"`
+
+	Packages  map[string]Package `yaml:"packages"`
+	Snapshots map[string]Package `yaml:"snapshots"` // v9 only
+}
+
+// LockScanner implements scanner.Scanner to extract facts from pnpm-lock.yaml files.
+type LockScanner struct{}
+
+func (p *LockScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == "pnpm-lock.yaml"
+}
+
+func (p *LockScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	var lock PnpmLock
+	if err = yaml.Unmarshal(content.Bytes(), &lock); err != nil {
+		return nil, err
+	}
+
+	// lockfile versions before 6.0 use a different format for package keys
+	var version, _ = strconv.ParseFloat(lock.LockfileVersion, 64)
+	var legacy = version < 6
+
+	var importers = lock.Importers
+	if len(importers) == 0 {
+		importers = map[string]Importer{".": lock.Importer}
+	}
+
+	for _, dir := range sortedKeys(importers) {
+		var importer = importers[dir]
+		emit("@node/pnpm/importer", map[string]any{"path": dir})
+
+		var deps = func(deps map[string]Dependency, typ string) {
+			for _, name := range sortedKeys(deps) {
+				var dep = deps[name]
+				if dep.Specifier == "" { // v5, with specifiers recorded separately
+					dep.Specifier = importer.Specifiers[name]
+				}
+				emit("@node/pnpm/dependency", map[string]any{"importer": dir, "name": name, "type": typ, "specifier": dep.Specifier, "version": dep.Version})
+			}
+		}
+
+		deps(importer.Dependencies, "prod")
+		deps(importer.DevDependencies, "dev")
+		deps(importer.OptionalDependencies, "optional")
+	}
+
+	// since v9, the optional flag (which depends on how the package is used) is only recorded in snapshots,
+	// which are keyed by the package key with a (peer dependencies) suffix
+	var optional = make(map[string]bool)
+	for snapshot, s := range lock.Snapshots {
+		var key, _, _ = strings.Cut(snapshot, "(")
+		optional[key] = optional[key] || s.Optional
+	}
+
+	for _, key := range sortedKeys(lock.Packages) {
+		var pkg = lock.Packages[key]
+
+		var name, version = splitKey(key, legacy)
+		if pkg.Name != "" {
+			name, version = pkg.Name, pkg.Version
+		}
+
+		var val = map[string]any{
+			"path": key, "name": name, "version": version,
+			"integrity": pkg.Resolution.Integrity, "optional": pkg.Optional || optional[key],
+		}
+		if pkg.Resolution.Tarball != "" {
+			val["resolved"] = pkg.Resolution.Tarball
+		}
+		if pkg.Dev != nil {
+			val["dev"] = *pkg.Dev
+		}
+
+		emit("@node/pnpm/dependency-locked", val)
+	}
+
+	return facts, nil
+}
+
+// splitKey extracts the name and version from a package key; keys look like /@scope/name/1.0.0_peer@2.0.0 in v5,
+// /@scope/name@1.0.0(peer@2.0.0) in v6, and @scope/name@1.0.0 in v9 (where peer suffixes only appear in snapshots).
+func splitKey(key string, legacy bool) (name, version string) {
+	key = strings.TrimPrefix(key, "/")
+
+	if legacy {
+		var i = strings.LastIndex(key, "/")
+		if i < 0 {
+			return key, ""
+		}
+		name, version = key[:i], key[i+1:]
+		version, _, _ = strings.Cut(version, "_")
+		return name, version
+	}
+
+	key, _, _ = strings.Cut(key, "(")
+	if i := strings.Index(strings.TrimPrefix(key, "@"), "@"); i >= 0 {
+		if strings.HasPrefix(key, "@") {
+			i++
+		}
+		return key[:i], key[i+1:]
+	}
+	return key, ""
+}
+
+// sortedKeys returns the keys of the map in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	var keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// register the LockScanner with scanner registry
+func init() { scanner.Register("node/pnpm/lock", &LockScanner{}) }
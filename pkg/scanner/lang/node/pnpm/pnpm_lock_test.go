@@ -0,0 +1,75 @@
+package pnpm
+
+import (
+	"context"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"github.com/mergestat/kyc/pkg/scanner/scannertest"
+	"reflect"
+	"testing"
+)
+
+func TestLockScanner_Scan(t *testing.T) {
+	var cases = []struct {
+		name string
+		lock string
+		want []scanner.Fact
+	}{
+		{
+			name: "v6 unquoted versions",
+			lock: `
+lockfileVersion: '6.0'
+
+dependencies:
+  left-pad:
+    specifier: 1.10
+    version: 1.10.0
+
+packages:
+
+  /left-pad@1.10.0:
+    resolution: {integrity: sha512-abc}
+    dev: false
+`,
+			want: []scanner.Fact{
+				{Key: "@node/pnpm/importer", Value: map[string]any{"path": "."}},
+				{Key: "@node/pnpm/dependency", Value: map[string]any{"importer": ".", "name": "left-pad", "type": "prod", "specifier": "1.10", "version": "1.10.0"}},
+				{Key: "@node/pnpm/dependency-locked", Value: map[string]any{"path": "/left-pad@1.10.0", "name": "left-pad", "version": "1.10.0", "integrity": "sha512-abc", "optional": false, "dev": false}},
+			},
+		},
+		{
+			name: "v5 unquoted versions",
+			lock: `
+lockfileVersion: 5.4
+
+specifiers:
+  left-pad: 1.10
+
+devDependencies:
+  left-pad: 1.10
+
+packages:
+
+  /left-pad/1.10:
+    resolution: {integrity: sha512-abc}
+    dev: true
+`,
+			want: []scanner.Fact{
+				{Key: "@node/pnpm/importer", Value: map[string]any{"path": "."}},
+				{Key: "@node/pnpm/dependency", Value: map[string]any{"importer": ".", "name": "left-pad", "type": "dev", "specifier": "1.10", "version": "1.10"}},
+				{Key: "@node/pnpm/dependency-locked", Value: map[string]any{"path": "/left-pad/1.10", "name": "left-pad", "version": "1.10", "integrity": "sha512-abc", "optional": false, "dev": true}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got, err = (&LockScanner{}).Scan(context.Background(), scannertest.File("pnpm-lock.yaml", tc.lock))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package yarn
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BerryEntry represents a single entry of a yarn berry (v2+) lockfile. It is decoded with yaml.v3,
+// which retains the original text of scalars (so that an unquoted version like 1.10 isn't read as 1.1).
+type BerryEntry struct {
+	Version    string `yaml:"version"`
+	Resolution string `yaml:"resolution"`
+	Checksum   string `yaml:"checksum"`
+	LinkType   string `yaml:"linkType"`
+}
+
+// LockScanner implements scanner.Scanner to extract facts from yarn.lock files,
+// supporting both the classic (v1) format and the yaml-based format used by yarn berry (v2+).
+type LockScanner struct{}
+
+func (y *LockScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == "yarn.lock"
+}
+
+func (y *LockScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	// berry lockfiles always start with a __metadata entry, which classic lockfiles never have
+	if bytes.Contains(content.Bytes(), []byte("\n__metadata:")) || bytes.HasPrefix(content.Bytes(), []byte("__metadata:")) {
+		return scanBerry(content.Bytes())
+	}
+	return scanClassic(content.Bytes())
+}
+
+// scanClassic parses a classic yarn lockfile, which looks like yaml but isn't, as in:
+//
+//	"@babel/core@^7.0.0", "@babel/core@^7.1.0":
+//	  version "7.1.2"
+//	  resolved "https://registry.yarnpkg.com/@babel/core/-/core-7.1.2.tgz#..."
+//	  integrity sha512-...
+//	  dependencies:
+//	    "@babel/code-frame" "^7.0.0"
+func scanClassic(content []byte) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+
+	var val map[string]any
+	var flush = func() {
+		if val != nil {
+			facts = append(facts, scanner.Fact{Key: "@node/yarn/dependency-locked", Value: val})
+		}
+		val = nil
+	}
+
+	var lines = bufio.NewScanner(bytes.NewReader(content))
+	lines.Buffer(nil, len(content)+1) // allow lines as long as the whole content
+	for lines.Scan() {
+		var line = lines.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// an unindented line starts a new entry, listing all the requested specifiers resolving to it
+		if !strings.HasPrefix(line, " ") {
+			flush()
+
+			var name string
+			var ranges []string
+			for _, spec := range strings.Split(strings.TrimSuffix(line, ":"), ",") {
+				var n, r = splitSpec(unquote(strings.TrimSpace(spec)))
+				name, ranges = n, append(ranges, r)
+			}
+			val = map[string]any{"name": name, "ranges": ranges, "version": "", "resolved": "", "integrity": ""}
+			continue
+		}
+
+		// only the fields of the entry itself are of interest; nested sections (such as dependencies) are indented further
+		if val == nil || strings.HasPrefix(line, "    ") {
+			continue
+		}
+
+		var key, value, _ = strings.Cut(strings.TrimSpace(line), " ")
+		switch key = unquote(key); key {
+		case "version", "resolved", "integrity":
+			val[key] = unquote(value)
+		}
+	}
+	flush()
+
+	return facts, lines.Err()
+}
+
+// scanBerry parses a yarn berry lockfile, which is valid yaml, as in:
+//
+//	"@babel/core@npm:^7.0.0, @babel/core@npm:^7.1.0":
+//	  version: 7.1.2
+//	  resolution: "@babel/core@npm:7.1.2"
+//	  checksum: 0a1b2c...
+//	  languageName: node
+//	  linkType: hard
+func scanBerry(content []byte) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+
+	var entries map[string]BerryEntry
+	if err = yaml.Unmarshal(content, &entries); err != nil {
+		return nil, err
+	}
+
+	var keys = make([]string, 0, len(entries))
+	for key := range entries {
+		if key != "__metadata" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var entry = entries[key]
+
+		var name string
+		var ranges []string
+		for _, spec := range strings.Split(key, ",") {
+			var n, r = splitSpec(strings.TrimSpace(spec))
+			name, ranges = n, append(ranges, r)
+		}
+
+		// berry records a checksum of the cached archive, rather than an integrity hash of the tarball
+		var val = map[string]any{
+			"name": name, "ranges": ranges, "version": entry.Version,
+			"resolved": entry.Resolution, "checksum": entry.Checksum, "link_type": entry.LinkType,
+		}
+		facts = append(facts, scanner.Fact{Key: "@node/yarn/dependency-locked", Value: val})
+	}
+
+	return facts, nil
+}
+
+// splitSpec splits a specifier such as @scope/name@^1.0.0 (or @scope/name@npm:^1.0.0 for berry) into name and range
+func splitSpec(spec string) (name, rng string) {
+	// the range may itself contain an @ (as in aliases, like foo@npm:bar@^1.0.0), so we split on the first one after the scope
+	if i := strings.Index(strings.TrimPrefix(spec, "@"), "@"); i >= 0 {
+		if strings.HasPrefix(spec, "@") {
+			i++
+		}
+		return spec[:i], spec[i+1:]
+	}
+	return spec, ""
+}
+
+// unquote removes the double quotes around the string, if any
+func unquote(str string) string {
+	if s, err := strconv.Unquote(str); err == nil {
+		return s
+	}
+	return str
+}
+
+// register the LockScanner with scanner registry
+func init() { scanner.Register("node/yarn/lock", &LockScanner{}) }
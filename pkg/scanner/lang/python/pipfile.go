@@ -0,0 +1,99 @@
+package python
+
+import (
+	"context"
+	"github.com/BurntSushi/toml"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+)
+
+// PipfileScanner implements scanner.Scanner to extract facts from pipenv's Pipfile (https://github.com/pypa/pipfile).
+type PipfileScanner struct{}
+
+func (p *PipfileScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == "Pipfile"
+}
+
+func (p *PipfileScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// besides [packages] and [dev-packages], pipenv supports arbitrary package categories, so the file is decoded generically
+	var pipfile map[string]any
+	if _, err = toml.NewDecoder(reader).Decode(&pipfile); err != nil {
+		return nil, err
+	}
+
+	if requires, ok := pipfile["requires"].(map[string]any); ok {
+		for _, key := range []string{"python_version", "python_full_version"} {
+			if version, ok := requires[key].(string); ok {
+				emit("@python/pipfile/requires-python", map[string]any{"version": version, "exact": key == "python_full_version"})
+			}
+		}
+	}
+
+	for _, category := range sortedKeys(pipfile) {
+		var packages, ok = pipfile[category].(map[string]any)
+		if !ok || category == "requires" || category == "source" || category == "scripts" || category == "pipenv" {
+			continue
+		}
+
+		for _, name := range sortedKeys(packages) {
+			var val = pipfileDependency(name, packages[name])
+			val["category"] = category
+			emit("@python/pipfile/dependency", val)
+		}
+	}
+
+	return facts, nil
+}
+
+// pipfileDependency returns the attributes of a Pipfile dependency, declared either as a specifier (such as ">=2.0" or "*"),
+// or as a table (such as { version = ">=2.0", extras = ["socks"], markers = "os_name == 'nt'" }).
+func pipfileDependency(name string, spec any) map[string]any {
+	var val = map[string]any{"name": name, "normalized_name": normalizeName(name), "specifier": "", "extras": []string{}, "marker": ""}
+
+	var specifier = func(s string) string {
+		if s == "*" {
+			return "" // any version
+		}
+		return s
+	}
+
+	switch spec := spec.(type) {
+	case string:
+		val["specifier"] = specifier(spec)
+	case map[string]any:
+		if version, ok := spec["version"].(string); ok {
+			val["specifier"] = specifier(version)
+		}
+		if extras, ok := spec["extras"].([]any); ok {
+			val["extras"] = strs(extras)
+		}
+		if markers, ok := spec["markers"].(string); ok {
+			val["marker"] = markers
+		}
+		if editable, ok := spec["editable"].(bool); ok {
+			val["editable"] = editable
+		}
+		for _, source := range []string{"git", "path", "file"} {
+			if location, ok := spec[source].(string); ok {
+				val["url"] = location
+			}
+		}
+	}
+
+	return val
+}
+
+// register the PipfileScanner with scanner registry
+func init() { scanner.Register("python/pipfile", &PipfileScanner{}) }
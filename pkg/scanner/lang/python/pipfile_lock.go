@@ -0,0 +1,128 @@
+package python
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"strings"
+)
+
+// LockedRequirement represents a single locked package in Pipfile.lock
+type LockedRequirement struct {
+	Version string   `json:"version"` // pinned version, as in ==2.31.0
+	Hashes  []string `json:"hashes"`
+	Markers string   `json:"markers"`
+	Extras  []string `json:"extras"`
+	Index   string   `json:"index"`
+	Git     string   `json:"git"`
+	Ref     string   `json:"ref"`
+}
+
+// PipfileLock represents the subset of Pipfile.lock we are interested in. Apart from _meta, all top-level keys
+// are package categories, mapping names to LockedRequirement.
+type PipfileLock struct {
+	Meta struct {
+		Requires struct {
+			PythonVersion     string `json:"python_version"`
+			PythonFullVersion string `json:"python_full_version"`
+		} `json:"requires"`
+	} `json:"_meta"`
+}
+
+// the lockfile uses different names for the default categories than the Pipfile ([packages] and [dev-packages]);
+// custom categories use the same name in both
+var lockCategories = map[string]string{"default": "packages", "develop": "dev-packages"}
+
+// PipfileLockScanner implements scanner.Scanner to extract facts from Pipfile.lock files.
+type PipfileLockScanner struct{}
+
+func (p *PipfileLockScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == "Pipfile.lock"
+}
+
+func (p *PipfileLockScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content []byte
+	if content, err = io.ReadAll(reader); err != nil {
+		return nil, err
+	}
+
+	var lock PipfileLock
+	if err = json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	var categories map[string]json.RawMessage
+	if err = json.Unmarshal(content, &categories); err != nil {
+		return nil, err
+	}
+
+	var requires = lock.Meta.Requires
+	if requires.PythonVersion != "" {
+		emit("@python/pipfile/requires-python", map[string]any{"version": requires.PythonVersion, "exact": false})
+	}
+	if requires.PythonFullVersion != "" {
+		emit("@python/pipfile/requires-python", map[string]any{"version": requires.PythonFullVersion, "exact": true})
+	}
+
+	var locked = func(packages map[string]LockedRequirement, category string) {
+		for _, name := range sortedKeys(packages) {
+			var pkg = packages[name]
+
+			var extras, hashes = pkg.Extras, pkg.Hashes
+			if extras == nil {
+				extras = []string{}
+			}
+			if hashes == nil {
+				hashes = []string{}
+			}
+
+			var val = map[string]any{
+				"name": name, "normalized_name": normalizeName(name), "version": strings.TrimPrefix(pkg.Version, "=="),
+				"extras": extras, "marker": pkg.Markers, "hashes": hashes, "index": pkg.Index, "category": category,
+			}
+			if pkg.Git != "" {
+				val["url"], val["ref"] = pkg.Git, pkg.Ref
+			}
+			emit("@python/pipfile/dependency-locked", val)
+		}
+	}
+
+	// default and develop come first, followed by any custom categories
+	var names = []string{"default", "develop"}
+	for _, name := range sortedKeys(categories) {
+		if name != "_meta" && lockCategories[name] == "" {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		var packages map[string]LockedRequirement
+		if raw, ok := categories[name]; !ok || json.Unmarshal(raw, &packages) != nil {
+			continue // missing, or not a category at all (such as a key added by a newer version of pipenv)
+		}
+
+		var category = name
+		if c, ok := lockCategories[name]; ok {
+			category = c
+		}
+		locked(packages, category)
+	}
+
+	return facts, nil
+}
+
+// register the PipfileLockScanner with scanner registry
+func init() { scanner.Register("python/pipfile-lock", &PipfileLockScanner{}) }
@@ -0,0 +1,98 @@
+package python
+
+import (
+	"context"
+	"github.com/BurntSushi/toml"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+)
+
+// PoetryLock represents the subset of poetry.lock we are interested in.
+type PoetryLock struct {
+	Package []struct {
+		Name           string `toml:"name"`
+		Version        string `toml:"version"`
+		Category       string `toml:"category"` // removed in lockfile version 2.0
+		Optional       bool   `toml:"optional"`
+		PythonVersions string `toml:"python-versions"`
+		Files          []struct {
+			File string `toml:"file"`
+			Hash string `toml:"hash"`
+		} `toml:"files"`
+		Source struct {
+			Type      string `toml:"type"`
+			URL       string `toml:"url"`
+			Reference string `toml:"resolved_reference"`
+		} `toml:"source"`
+	} `toml:"package"`
+
+	Metadata struct {
+		LockVersion    string `toml:"lock-version"`
+		PythonVersions string `toml:"python-versions"`
+
+		// before lockfile version 1.1, hashes were listed in a separate table, keyed by package name
+		Files map[string][]struct {
+			Hash string `toml:"hash"`
+		} `toml:"files"`
+	} `toml:"metadata"`
+}
+
+// PoetryLockScanner implements scanner.Scanner to extract facts from poetry.lock files.
+type PoetryLockScanner struct{}
+
+func (p *PoetryLockScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == "poetry.lock"
+}
+
+func (p *PoetryLockScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var lock PoetryLock
+	if _, err = toml.NewDecoder(reader).Decode(&lock); err != nil {
+		return nil, err
+	}
+
+	if lock.Metadata.PythonVersions != "" {
+		emit("@python/poetry/requires-python", map[string]any{"specifier": lock.Metadata.PythonVersions})
+	}
+
+	for _, pkg := range lock.Package {
+		var hashes = []string{}
+		for _, file := range pkg.Files {
+			hashes = append(hashes, file.Hash)
+		}
+		for _, file := range lock.Metadata.Files[pkg.Name] {
+			hashes = append(hashes, file.Hash)
+		}
+
+		var val = map[string]any{
+			"name": pkg.Name, "normalized_name": normalizeName(pkg.Name), "version": pkg.Version,
+			"optional": pkg.Optional, "python_versions": pkg.PythonVersions, "hashes": hashes,
+		}
+		if pkg.Category != "" {
+			val["category"] = pkg.Category
+		}
+		if pkg.Source.Type != "" { // packages not resolved from pypi, such as git, directory or url dependencies
+			val["source_type"], val["url"] = pkg.Source.Type, pkg.Source.URL
+			if pkg.Source.Reference != "" {
+				val["ref"] = pkg.Source.Reference
+			}
+		}
+		emit("@python/poetry/dependency-locked", val)
+	}
+
+	return facts, nil
+}
+
+// register the PoetryLockScanner with scanner registry
+func init() { scanner.Register("python/poetry-lock", &PoetryLockScanner{}) }
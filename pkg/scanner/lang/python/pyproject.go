@@ -0,0 +1,188 @@
+package python
+
+import (
+	"context"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// PyProject represents the subset of pyproject.toml we are interested in; that is the PEP 621 [project] table,
+// the PEP 518 [build-system] table and the dependency tables of [tool.poetry].
+type PyProject struct {
+	BuildSystem struct {
+		Requires []string `toml:"requires"`
+	} `toml:"build-system"`
+
+	Project struct {
+		Name                 string              `toml:"name"`
+		Version              string              `toml:"version"`
+		RequiresPython       string              `toml:"requires-python"`
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+
+	Tool struct {
+		Poetry struct {
+			Name            string         `toml:"name"`
+			Version         string         `toml:"version"`
+			Dependencies    map[string]any `toml:"dependencies"`
+			DevDependencies map[string]any `toml:"dev-dependencies"` // deprecated in favour of groups
+			Group           map[string]struct {
+				Dependencies map[string]any `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// PyProjectScanner implements scanner.Scanner to extract facts from pyproject.toml files.
+type PyProjectScanner struct{}
+
+func (p *PyProjectScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == "pyproject.toml"
+}
+
+func (p *PyProjectScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var pyproject PyProject
+	if _, err = toml.NewDecoder(reader).Decode(&pyproject); err != nil {
+		return nil, err
+	}
+
+	// dependency emits a fact for a PEP 508 requirement string
+	var dependency = func(str, group, source string) {
+		if req, ok := parseRequirement(str); ok {
+			var val = req.value()
+			val["group"], val["source"] = group, source
+			emit("@python/pyproject/dependency", val)
+		}
+	}
+
+	for _, str := range pyproject.BuildSystem.Requires {
+		dependency(str, "build-system", "pep518")
+	}
+
+	var project = pyproject.Project
+	if project.Name != "" {
+		emit("@python/pyproject/project", map[string]any{"name": project.Name, "version": project.Version, "source": "pep621"})
+	}
+	if project.RequiresPython != "" {
+		emit("@python/pyproject/requires-python", map[string]any{"specifier": project.RequiresPython, "source": "pep621"})
+	}
+	for _, str := range project.Dependencies {
+		dependency(str, "main", "pep621")
+	}
+	for _, group := range sortedKeys(project.OptionalDependencies) {
+		for _, str := range project.OptionalDependencies[group] {
+			dependency(str, group, "pep621")
+		}
+	}
+
+	var poetry = pyproject.Tool.Poetry
+	if poetry.Name != "" {
+		emit("@python/pyproject/project", map[string]any{"name": poetry.Name, "version": poetry.Version, "source": "poetry"})
+	}
+
+	var poetryDependencies = func(deps map[string]any, group string) {
+		for _, name := range sortedKeys(deps) {
+			// the python "dependency" declares the supported python versions, rather than a package
+			if name == "python" {
+				emit("@python/pyproject/requires-python", map[string]any{"specifier": fmt.Sprint(deps[name]), "source": "poetry"})
+				continue
+			}
+
+			var val = poetryDependency(name, deps[name])
+			val["group"], val["source"] = group, "poetry"
+			emit("@python/pyproject/dependency", val)
+		}
+	}
+
+	poetryDependencies(poetry.Dependencies, "main")
+	poetryDependencies(poetry.DevDependencies, "dev")
+	for _, group := range sortedKeys(poetry.Group) {
+		poetryDependencies(poetry.Group[group].Dependencies, group)
+	}
+
+	return facts, nil
+}
+
+// poetryDependency returns the attributes of a poetry dependency, declared either as a constraint (such as ^2.1),
+// as a table (such as { version = "^2.1", extras = ["socks"] }), or as a list of tables with different markers.
+// Poetry constraints use their own syntax (with ^ and ~ operators), and are reported as-is.
+func poetryDependency(name string, spec any) map[string]any {
+	var val = map[string]any{"name": name, "normalized_name": normalizeName(name), "specifier": "", "extras": []string{}, "marker": ""}
+
+	switch spec := spec.(type) {
+	case string:
+		val["specifier"] = spec
+	case map[string]any:
+		if version, ok := spec["version"].(string); ok {
+			val["specifier"] = version
+		}
+		if extras, ok := spec["extras"].([]any); ok {
+			val["extras"] = strs(extras)
+		}
+		if markers, ok := spec["markers"].(string); ok {
+			val["marker"] = markers
+		}
+		if python, ok := spec["python"].(string); ok {
+			val["python"] = python
+		}
+		if optional, ok := spec["optional"].(bool); ok {
+			val["optional"] = optional
+		}
+		for _, source := range []string{"git", "path", "url"} {
+			if location, ok := spec[source].(string); ok {
+				val["url"] = location
+			}
+		}
+	case []any: // multiple constraints, each applying under different conditions
+		var specifiers []string
+		for _, s := range spec {
+			if s, ok := s.(map[string]any); ok && s["version"] != nil {
+				specifiers = append(specifiers, fmt.Sprint(s["version"]))
+			}
+		}
+		val["specifier"] = strings.Join(specifiers, " || ")
+	}
+
+	return val
+}
+
+// sortedKeys returns the keys of the map in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	var keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// strs returns the string elements of the list, skipping all others
+func strs(list []any) []string {
+	var result = []string{}
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// register the PyProjectScanner with scanner registry
+func init() { scanner.Register("python/pyproject", &PyProjectScanner{}) }
@@ -0,0 +1,98 @@
+package python
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Requirement represents a dependency specification, as defined by PEP 508 (https://peps.python.org/pep-0508/),
+// such as requests[security,socks] >= 2.8.1, == 2.8.* ; python_version < "2.7"
+type Requirement struct {
+	Name      string
+	Extras    []string
+	Specifier string // version specifier, such as >=2.8.1,==2.8.*
+	URL       string // for direct references, such as pip @ https://github.com/pypa/pip/archive/1.3.1.zip
+	Marker    string // environment marker, such as python_version < "2.7"
+}
+
+// matches the name and (optional) extras at the start of a requirement
+var requirementName = regexp.MustCompile(`^\s*([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)\s*(?:\[([^\]]*)\])?\s*`)
+
+// parseRequirement parses a PEP 508 requirement string. It returns false if the string does not start with a valid name,
+// or if what follows the name is neither a version specifier, a direct reference nor a marker.
+func parseRequirement(str string) (req Requirement, ok bool) {
+	var match = requirementName.FindStringSubmatchIndex(str)
+	if match == nil {
+		return req, false
+	}
+
+	req.Name = str[match[2]:match[3]]
+	if match[4] >= 0 {
+		for _, extra := range strings.Split(str[match[4]:match[5]], ",") {
+			if extra = strings.TrimSpace(extra); extra != "" {
+				req.Extras = append(req.Extras, extra)
+			}
+		}
+	}
+
+	var rest = strings.TrimSpace(str[match[1]:])
+	if strings.HasPrefix(rest, "@") {
+		// urls may contain a semicolon, so the marker of a direct reference must be preceded by whitespace
+		var url, marker, _ = cut(strings.TrimSpace(rest[1:]), " ;", "\t;")
+		req.URL, req.Marker = strings.TrimSpace(url), strings.TrimSpace(marker)
+		return req, req.URL != ""
+	}
+
+	var spec, marker, _ = strings.Cut(rest, ";")
+	req.Marker = strings.TrimSpace(marker)
+
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "(") && strings.HasSuffix(spec, ")") {
+		spec = spec[1 : len(spec)-1] // specifiers may be wrapped in parentheses, as in name (>=1.0)
+	}
+	if spec != "" && !strings.ContainsAny(spec[:1], "<>=!~") {
+		return req, false
+	}
+	req.Specifier = strings.Join(strings.Fields(spec), "")
+
+	return req, true
+}
+
+// value returns the attributes of the requirement, used as (part of) a fact value
+func (req *Requirement) value() map[string]any {
+	var extras = req.Extras
+	if extras == nil {
+		extras = []string{}
+	}
+
+	var val = map[string]any{
+		"name": req.Name, "normalized_name": normalizeName(req.Name),
+		"specifier": req.Specifier, "extras": extras, "marker": req.Marker,
+	}
+	if req.URL != "" {
+		val["url"] = req.URL
+	}
+	return val
+}
+
+var nameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizeName normalizes a project name as per PEP 503, so that names from different sources (which may differ
+// in case, or use different separators) can be compared with each other.
+func normalizeName(name string) string {
+	return strings.ToLower(nameSeparators.ReplaceAllString(name, "-"))
+}
+
+// cut slices s around the first instance of any of the separators
+func cut(s string, seps ...string) (before, after string, found bool) {
+	var at, length = -1, 0
+	for _, sep := range seps {
+		if i := strings.Index(s, sep); i >= 0 && (at < 0 || i < at) {
+			at, length = i, len(sep)
+		}
+	}
+	if at < 0 {
+		return s, "", false
+	}
+	return s[:at], s[at+length:], true
+}
@@ -0,0 +1,157 @@
+package python
+
+import (
+	"bufio"
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// matches requirements.txt and variants like requirements-dev.txt or requirements_test.txt
+var requirementsFileName = regexp.MustCompile(`^requirements([.\-_][\w.-]+)?\.txt$`)
+
+// matches (local) distribution archives, such as numpy-1.9.2-cp34-none-win32.whl, which look like valid names too
+var archiveName = regexp.MustCompile(`\.(whl|zip|tar\.gz|tgz)$`)
+
+// RequirementsScanner implements scanner.Scanner to extract facts from pip requirements files
+// (https://pip.pypa.io/en/stable/reference/requirements-file-format/).
+type RequirementsScanner struct{}
+
+func (r *RequirementsScanner) Supports(file *object.File) bool {
+	var name = path.Base(file.Name)
+
+	// files under a requirements/ directory (as in requirements/base.txt) are requirements files too
+	var nested = path.Base(path.Dir(file.Name)) == "requirements" && path.Ext(name) == ".txt"
+	return file.Mode.IsFile() && (requirementsFileName.MatchString(name) || nested)
+}
+
+func (r *RequirementsScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	for _, line := range logicalLines(reader) {
+		var editable bool
+		switch option, arg := splitOption(line); option {
+		case "":
+			// not an option; a regular requirement
+		case "-r", "--requirement", "-c", "--constraint":
+			// included files are resolved relative to the including file
+			var val = map[string]any{"path": arg, "constraint": option == "-c" || option == "--constraint"}
+			if !strings.Contains(arg, "://") {
+				val["path"] = path.Join(path.Dir(file.Name), arg)
+			}
+			emit("@python/requirements/include", val)
+			continue
+		case "-e", "--editable":
+			line, editable = arg, true
+		default:
+			continue // global options, such as --index-url, have no bearing on the dependencies
+		}
+
+		// per-requirement options (such as --hash) follow the requirement itself
+		if i := strings.Index(line, " --"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		var val map[string]any
+		if req, ok := parseRequirement(line); ok && !archiveName.MatchString(req.Name) {
+			val = req.value()
+		} else {
+			// a url or local path, such as git+https://github.com/org/repo#egg=name, or ./downloads/numpy-1.9.2.whl
+			var location, marker, _ = cut(line, " ;", "\t;")
+			val = map[string]any{"name": "", "normalized_name": "", "specifier": "", "extras": []string{}, "marker": strings.TrimSpace(marker), "url": location}
+			if _, fragment, ok := strings.Cut(location, "#egg="); ok {
+				var name, _, _ = strings.Cut(fragment, "&")
+				val["name"], val["normalized_name"] = name, normalizeName(name)
+			} else if name := archiveProject(location); name != "" {
+				val["name"], val["normalized_name"] = name, normalizeName(name)
+			}
+		}
+		val["editable"] = editable
+		emit("@python/requirements/dependency", val)
+	}
+
+	return facts, nil
+}
+
+// archiveProject returns the project name of a distribution archive, derived from its file name, as in numpy-1.9.2-cp34-none-win32.whl
+// for wheels (where dashes within the name are escaped as underscores) or numpy-1.9.2.tar.gz for source distributions.
+func archiveProject(location string) string {
+	var name = path.Base(location)
+	if !archiveName.MatchString(name) {
+		return ""
+	}
+	name = archiveName.ReplaceAllString(name, "")
+
+	if strings.HasSuffix(location, ".whl") {
+		name, _, _ = strings.Cut(name, "-")
+		return name
+	}
+
+	// source distributions are named <name>-<version>, where the name itself may contain dashes; archives not named
+	// that way (such as master.zip, as downloaded from github) say nothing of the project
+	if i := strings.LastIndex(name, "-"); i > 0 && i+1 < len(name) && name[i+1] >= '0' && name[i+1] <= '9' {
+		return name[:i]
+	}
+	return ""
+}
+
+// logicalLines returns the non-empty lines of a requirements file, with comments removed and continuations joined
+func logicalLines(reader io.Reader) []string {
+	var result []string
+	var current strings.Builder
+
+	var lines = bufio.NewScanner(reader)
+	for lines.Scan() {
+		var line = lines.Text()
+		if strings.HasSuffix(line, `\`) {
+			current.WriteString(strings.TrimSuffix(line, `\`))
+			continue
+		}
+		current.WriteString(line)
+		line = current.String()
+		current.Reset()
+
+		// comments start with a # at the beginning of the line, or after whitespace
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		line, _, _ = cut(line, " #", "\t#")
+
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+
+	if line := strings.TrimSpace(current.String()); line != "" && !strings.HasPrefix(line, "#") {
+		result = append(result, line)
+	}
+	return result
+}
+
+// splitOption splits a line starting with an option (such as -r base.txt or --requirement=base.txt) into option and argument
+func splitOption(line string) (option, arg string) {
+	if !strings.HasPrefix(line, "-") {
+		return "", line
+	}
+
+	var end = strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return line, ""
+	}
+	return line[:end], strings.TrimSpace(line[end+1:])
+}
+
+// register the RequirementsScanner with scanner registry
+func init() { scanner.Register("python/requirements", &RequirementsScanner{}) }
@@ -0,0 +1,107 @@
+package python
+
+import (
+	"bufio"
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"strings"
+)
+
+// SetupCfgScanner implements scanner.Scanner to extract facts from setuptools' declarative setup.cfg files
+// (https://setuptools.pypa.io/en/latest/userguide/declarative_config.html).
+type SetupCfgScanner struct{}
+
+func (s *SetupCfgScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == "setup.cfg"
+}
+
+func (s *SetupCfgScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var cfg map[string]map[string]string
+	if cfg, err = parseIni(reader); err != nil {
+		return nil, err
+	}
+
+	if metadata := cfg["metadata"]; metadata["name"] != "" {
+		emit("@python/setup-cfg/project", map[string]any{"name": metadata["name"], "version": metadata["version"]})
+	}
+
+	var options = cfg["options"]
+	if options["python_requires"] != "" {
+		emit("@python/setup-cfg/requires-python", map[string]any{"specifier": options["python_requires"]})
+	}
+
+	// dependencies are listed one per line; markers may contain semicolons, so lines are not split any further
+	var dependencies = func(list, group string) {
+		for _, line := range strings.Split(list, "\n") {
+			if req, ok := parseRequirement(strings.TrimSpace(line)); ok {
+				var val = req.value()
+				val["group"] = group
+				emit("@python/setup-cfg/dependency", val)
+			}
+		}
+	}
+
+	dependencies(options["install_requires"], "main")
+	dependencies(options["setup_requires"], "setup")
+	dependencies(options["tests_require"], "test")
+
+	var extras = cfg["options.extras_require"]
+	for _, group := range sortedKeys(extras) {
+		dependencies(extras[group], group)
+	}
+
+	return facts, nil
+}
+
+// parseIni parses an ini-style configuration file, in the dialect supported by python's configparser:
+// values may span multiple lines, with continuation lines indented deeper than the key.
+func parseIni(reader io.Reader) (map[string]map[string]string, error) {
+	var cfg = make(map[string]map[string]string)
+	var section, key string
+
+	var lines = bufio.NewScanner(reader)
+	for lines.Scan() {
+		var line = lines.Text()
+		var trimmed = strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			continue
+		}
+
+		// indented lines continue the value of the previous key
+		if (line[0] == ' ' || line[0] == '\t') && key != "" {
+			cfg[section][key] = strings.TrimSpace(cfg[section][key] + "\n" + trimmed)
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section, key = strings.TrimSpace(trimmed[1:len(trimmed)-1]), ""
+			if cfg[section] == nil {
+				cfg[section] = make(map[string]string)
+			}
+			continue
+		}
+
+		if i := strings.IndexAny(trimmed, "=:"); i > 0 && cfg[section] != nil {
+			key = strings.TrimSpace(trimmed[:i])
+			cfg[section][key] = strings.TrimSpace(trimmed[i+1:])
+		}
+	}
+
+	return cfg, lines.Err()
+}
+
+// register the SetupCfgScanner with scanner registry
+func init() { scanner.Register("python/setup-cfg", &SetupCfgScanner{}) }
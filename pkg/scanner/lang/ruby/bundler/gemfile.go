@@ -0,0 +1,141 @@
+package bundler
+
+import (
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	sitter "github.com/smacker/go-tree-sitter"
+	"path"
+)
+
+// GemfileScanner implements scanner.Scanner to extract facts from bundler's Gemfile (https://bundler.io/man/gemfile.5.html).
+type GemfileScanner struct{}
+
+func (g *GemfileScanner) Supports(file *object.File) bool {
+	var name = path.Base(file.Name)
+	return file.Mode.IsFile() && (name == "Gemfile" || name == "gems.rb")
+}
+
+func (g *GemfileScanner) Scan(ctx context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	var tree *sitter.Tree
+	var content []byte
+	if tree, content, err = parse(ctx, file); err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	for _, c := range calls(tree.RootNode(), content) {
+		if c.receiver != "" {
+			continue
+		}
+
+		switch c.method {
+		case "source":
+			// source blocks scope the gems within them, and are reported with each gem instead
+			if url, ok := first(c.args); ok && c.node.ChildByFieldName("block") == nil {
+				emit("@ruby/bundler/source", map[string]any{"url": url})
+			}
+		case "ruby":
+			var val = map[string]any{"requirements": strs(c.args...)}
+			if file, ok := c.options["file"].(string); ok {
+				val["file"] = file // as in ruby file: ".ruby-version"
+			}
+			for _, opt := range []string{"engine", "engine_version", "patchlevel"} {
+				if v, ok := c.options[opt]; ok {
+					val[opt] = v
+				}
+			}
+			emit("@ruby/bundler/ruby-version", val)
+		case "gemspec":
+			var val = map[string]any{"path": "."}
+			if p, ok := c.options["path"].(string); ok {
+				val["path"] = p
+			}
+			if name, ok := c.options["name"].(string); ok {
+				val["name"] = name
+			}
+			emit("@ruby/bundler/gemspec", val)
+		case "gem":
+			if name, ok := first(c.args); ok {
+				emit("@ruby/bundler/dependency", gem(name, c, content))
+			}
+		}
+	}
+
+	return facts, nil
+}
+
+// gem returns the attributes of a gem declaration, taking into account the blocks (such as group or git) it is declared in
+func gem(name string, c *call, content []byte) map[string]any {
+	var groups, platforms = strs(c.options["group"], c.options["groups"]), strs(c.options["platform"], c.options["platforms"])
+	var source = map[string]any{"source": "rubygems"}
+
+	// explicit sources take precedence over the ones from enclosing blocks
+	var sourced = false
+	var from = func(options map[string]any, kind string, location any) {
+		if sourced {
+			return
+		}
+		sourced = true
+
+		source = map[string]any{"source": kind, kind: location}
+		for _, ref := range []string{"branch", "tag", "ref"} {
+			if v, ok := options[ref]; ok {
+				source[ref] = v
+			}
+		}
+	}
+	for _, kind := range []string{"git", "github", "path"} {
+		if location, ok := c.options[kind]; ok {
+			from(c.options, kind, location)
+		}
+	}
+	if url, ok := c.options["source"].(string); ok && !sourced {
+		source, sourced = map[string]any{"source": "rubygems", "url": url}, true
+	}
+
+	for _, block := range enclosing(c.node, content) {
+		switch block.method {
+		case "group":
+			groups = append(groups, strs(block.args...)...)
+		case "platforms", "platform":
+			platforms = append(platforms, strs(block.args...)...)
+		case "git", "github", "path":
+			if location, ok := first(block.args); ok {
+				from(block.options, block.method, location)
+			}
+		case "source":
+			if url, ok := first(block.args); ok && !sourced {
+				source, sourced = map[string]any{"source": "rubygems", "url": url}, true
+			}
+		}
+	}
+
+	if len(groups) == 0 {
+		groups = []string{"default"}
+	}
+
+	var val = map[string]any{"name": name, "requirements": strs(c.args[1:]...), "groups": groups, "platforms": platforms}
+	if require, ok := c.options["require"]; ok {
+		val["require"] = require
+	}
+	for key, v := range source {
+		val[key] = v
+	}
+	return val
+}
+
+// first returns the first argument, if it is a string
+func first(args []any) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	var str, ok = args[0].(string)
+	return str, ok
+}
+
+// register the GemfileScanner with scanner registry
+func init() { scanner.Register("ruby/bundler/gemfile", &GemfileScanner{}) }
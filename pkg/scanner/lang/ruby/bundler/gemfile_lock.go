@@ -0,0 +1,124 @@
+package bundler
+
+import (
+	"bufio"
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"strings"
+)
+
+// GemfileLockScanner implements scanner.Scanner to extract facts from bundler's Gemfile.lock, which looks like:
+//
+//	GIT
+//	  remote: https://github.com/heartcombo/devise.git
+//	  revision: 0a1b2c3d
+//	  specs:
+//	    devise (4.9.2)
+//
+//	GEM
+//	  remote: https://rubygems.org/
+//	  specs:
+//	    nokogiri (1.15.0-x86_64-linux)
+//	      racc (~> 1.4)
+//
+//	RUBY VERSION
+//	   ruby 3.2.2p53
+type GemfileLockScanner struct{}
+
+func (g *GemfileLockScanner) Supports(file *object.File) bool {
+	var name = path.Base(file.Name)
+	return file.Mode.IsFile() && (name == "Gemfile.lock" || name == "gems.locked")
+}
+
+func (g *GemfileLockScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var section string
+	var source map[string]any // attributes of the current GIT, PATH or GEM source
+	var locked []map[string]any
+	var checksums = make(map[string]string) // only recorded since bundler 2.5, keyed by name (version)
+
+	var lines = bufio.NewScanner(reader)
+	for lines.Scan() {
+		var line = lines.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// unindented lines start a new section
+		if !strings.HasPrefix(line, " ") {
+			section, source = line, nil
+			switch section {
+			case "GIT", "PATH", "GEM", "PLUGIN SOURCE":
+				source = map[string]any{"source": strings.ToLower(strings.Fields(section)[0])}
+			}
+			continue
+		}
+
+		var indent = len(line) - len(strings.TrimLeft(line, " "))
+		var text = strings.TrimSpace(line)
+
+		switch {
+		case source != nil && indent == 2:
+			// source attributes, such as remote, revision, branch or tag
+			if key, value, ok := strings.Cut(text, ": "); ok {
+				source[key] = value
+			}
+		case source != nil && indent == 4:
+			// locked specs; the (indented further) dependencies of each spec are not of interest here
+			var name, version = spec(text)
+			var val = map[string]any{"name": name, "version": version, "platform": "ruby"}
+			if v, platform, found := strings.Cut(version, "-"); found {
+				val["version"], val["platform"] = v, platform // platform-specific gems, as in nokogiri (1.15.0-x86_64-linux)
+			}
+			for key, v := range source {
+				val[key] = v
+			}
+			locked = append(locked, val)
+		case section == "CHECKSUMS" && indent == 2:
+			if spec, checksum, ok := strings.Cut(text, " sha256="); ok {
+				checksums[spec] = "sha256=" + checksum
+			}
+		case section == "RUBY VERSION":
+			emit("@ruby/bundler/ruby-version-locked", map[string]any{"version": strings.TrimPrefix(text, "ruby ")})
+		case section == "BUNDLED WITH":
+			emit("@ruby/bundler/bundler-version", map[string]any{"version": text})
+		}
+	}
+	if err = lines.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, val := range locked {
+		var key = val["name"].(string) + " (" + val["version"].(string)
+		if val["platform"] != "ruby" {
+			key += "-" + val["platform"].(string)
+		}
+		if checksum, ok := checksums[key+")"]; ok {
+			val["checksum"] = checksum
+		}
+		emit("@ruby/bundler/dependency-locked", val)
+	}
+
+	return facts, nil
+}
+
+// spec splits a locked spec, as in rails (7.0.4), into name and version
+func spec(text string) (name, version string) {
+	name, version, _ = strings.Cut(text, " ")
+	return name, strings.Trim(version, "()")
+}
+
+// register the GemfileLockScanner with scanner registry
+func init() { scanner.Register("ruby/bundler/gemfile-lock", &GemfileLockScanner{}) }
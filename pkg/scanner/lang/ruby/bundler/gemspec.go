@@ -0,0 +1,74 @@
+package bundler
+
+import (
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	utils "github.com/mergestat/kyc/pkg/tree-sitter-utils"
+	sitter "github.com/smacker/go-tree-sitter"
+	"path"
+)
+
+// GemspecScanner implements scanner.Scanner to extract facts from gem specifications (https://guides.rubygems.org/specification-reference/).
+type GemspecScanner struct{}
+
+func (g *GemspecScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Ext(file.Name) == ".gemspec"
+}
+
+func (g *GemspecScanner) Scan(ctx context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	var tree *sitter.Tree
+	var content []byte
+	if tree, content, err = parse(ctx, file); err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	// attributes are set using assignments, as in spec.name = "rails"; values that are not literals
+	// (such as spec.version = Rails::VERSION) are reported using their source text.
+	var attributes = make(map[string]any)
+	for _, node := range utils.Find(tree.RootNode(), func(node *sitter.Node) bool { return node.Type() == "assignment" }) {
+		var left, right = node.ChildByFieldName("left"), node.ChildByFieldName("right")
+		if left == nil || right == nil || left.Type() != "call" || left.ChildByFieldName("method") == nil {
+			continue
+		}
+
+		var value, ok = literal(right, content)
+		if !ok {
+			value = right.Content(content)
+		}
+		attributes[left.ChildByFieldName("method").Content(content)] = value
+	}
+
+	if name, ok := attributes["name"]; ok {
+		emit("@ruby/gemspec/gem", map[string]any{"name": name, "version": attributes["version"]})
+	}
+	if requirement, ok := attributes["required_ruby_version"]; ok {
+		emit("@ruby/gemspec/required-ruby-version", map[string]any{"requirements": strs(requirement)})
+	}
+
+	for _, c := range calls(tree.RootNode(), content) {
+		var typ string
+		switch c.method {
+		case "add_dependency", "add_runtime_dependency":
+			typ = "runtime"
+		case "add_development_dependency":
+			typ = "development"
+		default:
+			continue
+		}
+
+		if name, ok := first(c.args); ok {
+			var val = map[string]any{"name": name, "requirements": strs(c.args[1:]...), "type": typ}
+			emit("@ruby/gemspec/dependency", val)
+		}
+	}
+
+	return facts, nil
+}
+
+// register the GemspecScanner with scanner registry
+func init() { scanner.Register("ruby/gemspec", &GemspecScanner{}) }
@@ -0,0 +1,168 @@
+package bundler
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	utils "github.com/mergestat/kyc/pkg/tree-sitter-utils"
+	sitter "github.com/smacker/go-tree-sitter"
+	"io"
+	"strings"
+)
+
+// Gemfiles and gemspecs are ruby programs; rather than evaluating them, we look for the method calls
+// making up their DSLs, and only consider arguments that are literals.
+
+// call represents a method call in a ruby program, such as gem "rails", "~> 7.0", require: false
+type call struct {
+	node     *sitter.Node
+	receiver string         // receiver of the method, as in spec for spec.add_dependency; empty for calls without receiver
+	method   string         // name of the method called
+	args     []any          // positional arguments; nil for those that are not literals
+	options  map[string]any // keyword arguments (or a trailing hash), such as require: false or :group => :test
+}
+
+// parse reads and parses the ruby program in file, returning the syntax tree along with the file content
+func parse(ctx context.Context, file *object.File) (_ *sitter.Tree, _ []byte, err error) {
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	var content []byte
+	if content, err = io.ReadAll(reader); err != nil {
+		return nil, nil, err
+	}
+
+	var tree *sitter.Tree
+	if tree, err = utils.Parse(ctx, utils.GetLanguage("ruby"), content); err != nil {
+		return nil, nil, err
+	}
+	return tree, content, nil
+}
+
+// statements lists the node types whose children are statements
+var statements = map[string]bool{
+	"program": true, "body_statement": true, "do_block": true, "block": true, "block_body": true,
+	"then": true, "else": true, "begin": true, "ensure": true, "parenthesized_statements": true,
+}
+
+// calls returns all method calls in the tree, in source order. A bare identifier used as a statement
+// (such as gemspec on its own line) is parsed as such, but can only be a call without arguments.
+func calls(root *sitter.Node, content []byte) []*call {
+	var result []*call
+	_ = utils.PreOrder(context.Background(), root, func(node *sitter.Node) utils.Action {
+		switch {
+		case node.Type() == "call" && node.ChildByFieldName("method") != nil:
+			result = append(result, newCall(node, content))
+		case node.Type() == "identifier" && node.Parent() != nil && statements[node.Parent().Type()]:
+			result = append(result, &call{node: node, method: node.Content(content), options: make(map[string]any)})
+		}
+		return utils.Continue
+	})
+	return result
+}
+
+// newCall decodes the method call represented by node
+func newCall(node *sitter.Node, content []byte) *call {
+	var c = &call{node: node, method: node.ChildByFieldName("method").Content(content), options: make(map[string]any)}
+	if receiver := node.ChildByFieldName("receiver"); receiver != nil {
+		c.receiver = receiver.Content(content)
+	}
+
+	var args = node.ChildByFieldName("arguments")
+	if args == nil {
+		return c
+	}
+
+	for i := 0; i < int(args.NamedChildCount()); i++ {
+		var arg = args.NamedChild(i)
+		switch arg.Type() {
+		case "pair":
+			var key, _ = literal(arg.ChildByFieldName("key"), content)
+			var value, _ = literal(arg.ChildByFieldName("value"), content)
+			c.options[fmt.Sprint(key)] = value
+		case "hash": // explicit trailing hash, as in gem "x", { require: false }
+			for j := 0; j < int(arg.NamedChildCount()); j++ {
+				if pair := arg.NamedChild(j); pair.Type() == "pair" {
+					var key, _ = literal(pair.ChildByFieldName("key"), content)
+					var value, _ = literal(pair.ChildByFieldName("value"), content)
+					c.options[fmt.Sprint(key)] = value
+				}
+			}
+		default:
+			var value, _ = literal(arg, content)
+			c.args = append(c.args, value)
+		}
+	}
+
+	return c
+}
+
+// enclosing returns the calls whose blocks enclose the given node, innermost first; as in group :test do ... end
+func enclosing(node *sitter.Node, content []byte) []*call {
+	var result []*call
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		if parent.Type() == "call" && parent.ChildByFieldName("method") != nil && parent.ChildByFieldName("block") != nil {
+			result = append(result, newCall(parent, content))
+		}
+	}
+	return result
+}
+
+// literal returns the value of a literal expression (strings, symbols, booleans, numbers, and arrays thereof).
+// Strings with interpolation, constants and other expressions cannot be evaluated, and return false.
+func literal(node *sitter.Node, content []byte) (any, bool) {
+	if node == nil {
+		return nil, false
+	}
+
+	switch node.Type() {
+	case "string", "bare_string":
+		var str strings.Builder
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			switch child := node.NamedChild(i); child.Type() {
+			case "string_content", "escape_sequence":
+				str.WriteString(child.Content(content))
+			default:
+				return nil, false // interpolation
+			}
+		}
+		return str.String(), true
+	case "simple_symbol", "hash_key_symbol", "bare_symbol":
+		return strings.TrimPrefix(node.Content(content), ":"), true
+	case "array", "string_array", "symbol_array":
+		var values = []any{}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			var value, ok = literal(node.NamedChild(i), content)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, value)
+		}
+		return values, true
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	case "integer", "float":
+		return node.Content(content), true
+	}
+
+	return nil, false
+}
+
+// strs returns the string values, flattening arrays, as in gem "x", ["> 1", "< 2"] or group: [:dev, :test]
+func strs(values ...any) []string {
+	var result = []string{}
+	for _, v := range values {
+		switch v := v.(type) {
+		case string:
+			result = append(result, v)
+		case []any:
+			result = append(result, strs(v...)...)
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package cargo
+
+import (
+	"context"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"strings"
+)
+
+// Lockfile represents the subset of Cargo.lock we are interested in.
+type Lockfile struct {
+	Version int `toml:"version"` // only recorded since v3
+
+	Package []struct {
+		Name     string `toml:"name"`
+		Version  string `toml:"version"`
+		Source   string `toml:"source"`
+		Checksum string `toml:"checksum"`
+	} `toml:"package"`
+
+	// before v2, checksums were recorded in the metadata table, with keys like:
+	// "checksum serde 1.0.160 (registry+https://github.com/rust-lang/crates.io-index)"
+	Metadata map[string]string `toml:"metadata"`
+}
+
+// LockScanner implements scanner.Scanner to extract facts from Cargo.lock files.
+type LockScanner struct{}
+
+func (l *LockScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == "Cargo.lock"
+}
+
+func (l *LockScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var lock Lockfile
+	if _, err = toml.NewDecoder(reader).Decode(&lock); err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range lock.Package {
+		var checksum = pkg.Checksum
+		if checksum == "" && pkg.Source != "" {
+			checksum = lock.Metadata[fmt.Sprintf("checksum %s %s (%s)", pkg.Name, pkg.Version, pkg.Source)]
+		}
+
+		// packages without a source are members of the workspace (or path dependencies)
+		var val = map[string]any{"name": pkg.Name, "version": pkg.Version, "source": pkg.Source, "checksum": checksum, "local": pkg.Source == ""}
+		if kind, _, found := strings.Cut(pkg.Source, "+"); found {
+			val["source_kind"] = kind // registry, sparse or git
+		}
+		facts = append(facts, scanner.Fact{Key: "@rust/cargo/dependency-locked", Value: val})
+	}
+
+	return facts, nil
+}
+
+// register the LockScanner with scanner registry
+func init() { scanner.Register("rust/cargo/lock", &LockScanner{}) }
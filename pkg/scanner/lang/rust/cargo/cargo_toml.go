@@ -0,0 +1,195 @@
+package cargo
+
+import (
+	"context"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"sort"
+)
+
+// Dependencies maps names to dependencies, declared either as a version requirement (such as "1.0"),
+// or as a table (such as { version = "1.0", features = ["derive"] }); these are decoded as-is.
+type Dependencies map[string]any
+
+// DependencyTables represents the dependency tables that may appear at the top-level, or under a [target.<cfg>] table
+type DependencyTables struct {
+	Dependencies      Dependencies `toml:"dependencies"`
+	DevDependencies   Dependencies `toml:"dev-dependencies"`
+	BuildDependencies Dependencies `toml:"build-dependencies"`
+}
+
+// Manifest represents the subset of Cargo.toml (https://doc.rust-lang.org/cargo/reference/manifest.html) we are interested in.
+type Manifest struct {
+	Package struct {
+		Name string `toml:"name"`
+
+		// these may be inherited from the workspace, as in version.workspace = true
+		Version     any `toml:"version"`
+		Edition     any `toml:"edition"`
+		RustVersion any `toml:"rust-version"`
+		License     any `toml:"license"`
+	} `toml:"package"`
+
+	DependencyTables
+	Target map[string]DependencyTables `toml:"target"`
+
+	Workspace struct {
+		Members      []string     `toml:"members"`
+		Exclude      []string     `toml:"exclude"`
+		Dependencies Dependencies `toml:"dependencies"`
+	} `toml:"workspace"`
+}
+
+// ManifestScanner implements scanner.Scanner to extract facts from Cargo.toml manifests.
+type ManifestScanner struct{}
+
+func (m *ManifestScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == "Cargo.toml"
+}
+
+func (m *ManifestScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var manifest Manifest
+	if _, err = toml.NewDecoder(reader).Decode(&manifest); err != nil {
+		return nil, err
+	}
+
+	// virtual manifests (the root of a workspace) have no [package] table
+	if pkg := manifest.Package; pkg.Name != "" {
+		var val = map[string]any{"name": pkg.Name}
+		var inherited = []string{} // fields inherited from the workspace (as in version.workspace = true), which are left empty
+		for name, v := range map[string]any{"version": pkg.Version, "edition": pkg.Edition, "rust_version": pkg.RustVersion, "license": pkg.License} {
+			var value, fromWorkspace = field(v)
+			val[name] = value
+			if fromWorkspace {
+				inherited = append(inherited, name)
+			}
+		}
+		sort.Strings(inherited)
+		val["inherited"] = inherited
+		emit("@rust/cargo/package", val)
+	}
+
+	var dependencies = func(tables DependencyTables, target string) {
+		var kinds = []string{"normal", "dev", "build"}
+		for i, deps := range []Dependencies{tables.Dependencies, tables.DevDependencies, tables.BuildDependencies} {
+			var kind = kinds[i]
+			for _, name := range sortedKeys(deps) {
+				var val = dependency(name, deps[name])
+				val["kind"], val["target"] = kind, target
+				emit("@rust/cargo/dependency", val)
+			}
+		}
+	}
+
+	dependencies(manifest.DependencyTables, "")
+	for _, target := range sortedKeys(manifest.Target) {
+		dependencies(manifest.Target[target], target) // platform-specific dependencies, as in [target.'cfg(unix)'.dependencies]
+	}
+
+	for _, pattern := range manifest.Workspace.Members {
+		emit("@rust/cargo/workspace-member", map[string]any{"pattern": pattern, "excluded": false})
+	}
+	for _, pattern := range manifest.Workspace.Exclude {
+		emit("@rust/cargo/workspace-member", map[string]any{"pattern": pattern, "excluded": true})
+	}
+	for _, name := range sortedKeys(manifest.Workspace.Dependencies) {
+		emit("@rust/cargo/workspace-dependency", dependency(name, manifest.Workspace.Dependencies[name]))
+	}
+
+	return facts, nil
+}
+
+// dependency returns the attributes of a dependency, declared either as a version requirement or as a table
+func dependency(name string, spec any) map[string]any {
+	// package is the name of the crate, which differs from name for renamed dependencies
+	var val = map[string]any{
+		"name": name, "package": name, "requirement": "", "features": []string{},
+		"optional": false, "default_features": true, "source": "registry",
+	}
+
+	switch spec := spec.(type) {
+	case string:
+		val["requirement"] = spec
+	case map[string]any:
+		if version, ok := spec["version"].(string); ok {
+			val["requirement"] = version
+		}
+		if pkg, ok := spec["package"].(string); ok {
+			val["package"] = pkg
+		}
+		if features, ok := spec["features"].([]any); ok {
+			var list = []string{}
+			for _, feature := range features {
+				list = append(list, fmt.Sprint(feature))
+			}
+			val["features"] = list
+		}
+		if optional, ok := spec["optional"].(bool); ok {
+			val["optional"] = optional
+		}
+		for _, key := range []string{"default-features", "default_features"} {
+			if defaults, ok := spec[key].(bool); ok {
+				val["default_features"] = defaults
+			}
+		}
+		if registry, ok := spec["registry"].(string); ok {
+			val["registry"] = registry
+		}
+
+		switch {
+		case spec["workspace"] == true: // inherited from [workspace.dependencies]
+			val["source"] = "workspace"
+		case spec["git"] != nil:
+			val["source"], val["git"] = "git", spec["git"]
+			for _, ref := range []string{"branch", "tag", "rev"} {
+				if spec[ref] != nil {
+					val[ref] = spec[ref]
+				}
+			}
+		case spec["path"] != nil && spec["version"] == nil: // path dependencies with a version use the registry when published
+			val["source"], val["path"] = "path", spec["path"]
+		case spec["path"] != nil:
+			val["path"] = spec["path"]
+		}
+	}
+
+	return val
+}
+
+// field formats a package field, which is either a value, or a table inheriting the value from the workspace
+func field(v any) (_ any, inherited bool) {
+	if table, ok := v.(map[string]any); ok && table["workspace"] == true {
+		return "", true
+	}
+	if v == nil {
+		return "", false
+	}
+	return v, false
+}
+
+// sortedKeys returns the keys of the map in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	var keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// register the ManifestScanner with scanner registry
+func init() { scanner.Register("rust/cargo/manifest", &ManifestScanner{}) }
@@ -3,6 +3,7 @@ package scanner
 import (
 	"context"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"sync"
 )
 
 type Fact struct {
@@ -19,6 +20,45 @@ type Scanner interface {
 	Scan(ctx context.Context, file *object.File, keys ...string) ([]Fact, error)
 }
 
+type treeKey struct{}
+
+// treeScope is the context value set by WithTree, holding the tree along with values cached for it
+type treeScope struct {
+	tree  *object.Tree
+	cache sync.Map
+}
+
+// WithTree returns a copy of the context carrying the tree the scanned files belong to.
+// Scanners that need to look at other files in the tree (such as a parent manifest) can retrieve it using TreeFromContext.
+func WithTree(ctx context.Context, tree *object.Tree) context.Context {
+	return context.WithValue(ctx, treeKey{}, &treeScope{tree: tree})
+}
+
+// TreeFromContext returns the tree stored in the context by WithTree, if any.
+func TreeFromContext(ctx context.Context) (*object.Tree, bool) {
+	var scope, _ = ctx.Value(treeKey{}).(*treeScope)
+	if scope == nil {
+		return nil, false
+	}
+	return scope.tree, scope.tree != nil
+}
+
+// Cached returns the value cached under key for the tree stored in the context, calling fn to compute it on first use.
+// This lets scanners share work (such as parsing a parent manifest) across the files of a tree. Without a tree
+// in the context, fn is called every time.
+func Cached(ctx context.Context, key any, fn func() any) any {
+	var scope, _ = ctx.Value(treeKey{}).(*treeScope)
+	if scope == nil {
+		return fn()
+	}
+
+	if val, ok := scope.cache.Load(key); ok {
+		return val
+	}
+	var val, _ = scope.cache.LoadOrStore(key, fn())
+	return val
+}
+
 // collection of all registered scanners
 var scanners = make(map[string]Scanner)
 
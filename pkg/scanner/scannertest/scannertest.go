@@ -0,0 +1,21 @@
+// Package scannertest provides helpers to test scanners against in-memory files.
+package scannertest
+
+import (
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// File returns a regular file with the given name and content, backed by an in-memory blob
+func File(name, content string) *object.File {
+	var obj = &plumbing.MemoryObject{}
+	obj.SetType(plumbing.BlobObject)
+	_, _ = obj.Write([]byte(content))
+
+	var blob, err = object.DecodeBlob(obj)
+	if err != nil {
+		panic(err) // cannot happen for a blob object
+	}
+	return object.NewFile(name, filemode.Regular, blob)
+}
@@ -0,0 +1,152 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+)
+
+// matches azure-pipelines.yml, .azure-pipelines.yaml and variants like azure-pipelines.release.yml
+var pipelinesFileName = regexp.MustCompile(`^\.?azure-pipelines(\.[\w.-]+)?\.ya?ml$`)
+
+// PipelinesScanner implements scanner.Scanner to extract facts from Azure Pipelines configuration files.
+type PipelinesScanner struct{}
+
+func (p *PipelinesScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && pipelinesFileName.MatchString(path.Base(file.Name))
+}
+
+func (p *PipelinesScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	var pipeline map[string]any
+	if err = yaml.Unmarshal(content.Bytes(), &pipeline); err != nil {
+		return nil, err
+	}
+
+	// templates can be referenced from stages, jobs, steps and variables lists, or be extended from
+	var templates func(string, any)
+	templates = func(kind string, value any) {
+		switch value := value.(type) {
+		case map[string]any:
+			for _, key := range sortedKeys(value) {
+				var v = value[key]
+				if template, ok := v.(string); ok && key == "template" {
+					emit("@azure/pipelines/template", map[string]any{"template": template, "kind": kind})
+				} else {
+					templates(key, v)
+				}
+			}
+		case []any:
+			for _, v := range value {
+				templates(kind, v)
+			}
+		}
+	}
+	templates("", pipeline)
+
+	// images emits facts for the vm image and container used by a job (or the whole pipeline)
+	var images = func(stage, job string, spec map[string]any) {
+		switch pool := spec["pool"].(type) {
+		case string:
+			emit("@azure/pipelines/image", map[string]any{"stage": stage, "job": job, "pool": pool, "kind": "pool"})
+		case map[string]any:
+			if pool["vmImage"] != nil {
+				emit("@azure/pipelines/image", map[string]any{"stage": stage, "job": job, "image": pool["vmImage"], "kind": "vm"})
+			}
+			if pool["name"] != nil {
+				emit("@azure/pipelines/image", map[string]any{"stage": stage, "job": job, "pool": pool["name"], "kind": "pool"})
+			}
+		}
+
+		switch container := spec["container"].(type) {
+		case string: // either an image, or a reference to a container resource
+			emit("@azure/pipelines/image", map[string]any{"stage": stage, "job": job, "image": container, "kind": "container"})
+		case map[string]any:
+			emit("@azure/pipelines/image", map[string]any{"stage": stage, "job": job, "image": container["image"], "kind": "container"})
+		}
+	}
+	images("", "", pipeline)
+
+	// container resources declare images that jobs may refer to by name
+	if resources, ok := pipeline["resources"].(map[string]any); ok {
+		for _, c := range list(resources["containers"]) {
+			emit("@azure/pipelines/image", map[string]any{"container": c["container"], "image": c["image"], "kind": "resource"})
+		}
+	}
+
+	var jobs = func(stage string, value any) {
+		for _, job := range list(value) {
+			// deployment jobs are declared using the deployment keyword, instead of job
+			var name = job["job"]
+			if name == nil {
+				name = job["deployment"]
+			}
+			if name == nil {
+				continue // template reference
+			}
+
+			emit("@azure/pipelines/job", map[string]any{"stage": stage, "job": fmt.Sprint(name), "deployment": job["deployment"] != nil})
+			images(stage, fmt.Sprint(name), job)
+		}
+	}
+
+	for _, stage := range list(pipeline["stages"]) {
+		if stage["stage"] == nil {
+			continue // template reference
+		}
+
+		var name = fmt.Sprint(stage["stage"])
+		emit("@azure/pipelines/stage", map[string]any{"stage": name})
+		jobs(name, stage["jobs"])
+	}
+	jobs("", pipeline["jobs"])
+
+	return facts, nil
+}
+
+// list returns all items of the value (which is expected to be a list) that are maps
+func list(value any) []map[string]any {
+	var result []map[string]any
+	if items, ok := value.([]any); ok {
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				result = append(result, m)
+			}
+		}
+	}
+	return result
+}
+
+// sortedKeys returns the keys of the map in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	var keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// register the PipelinesScanner with scanner registry
+func init() { scanner.Register("azure/pipelines", &PipelinesScanner{}) }
@@ -0,0 +1,135 @@
+package circleci
+
+import (
+	"bytes"
+	"context"
+	"github.com/ghodss/yaml"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Executor represents the execution environment of a job (or of a reusable executor)
+type Executor struct {
+	Docker []struct {
+		Image string `json:"image"`
+	} `json:"docker"`
+	Machine any `json:"machine"` // either true or a map with an image key
+	Macos   any `json:"macos"`
+}
+
+// Config represents the subset of .circleci/config.yml we are interested in.
+type Config struct {
+	Orbs      map[string]any      `json:"orbs"`
+	Executors map[string]Executor `json:"executors"`
+	Jobs      map[string]struct {
+		Executor
+		Uses any `json:"executor"` // name of the reusable executor, either as string or as map with a name key
+	} `json:"jobs"`
+	Workflows map[string]any `json:"workflows"`
+}
+
+// ConfigScanner implements scanner.Scanner to extract facts from CircleCI configuration files.
+type ConfigScanner struct{}
+
+func (c *ConfigScanner) Supports(file *object.File) bool {
+	var ext = path.Ext(file.Name)
+	return file.Mode.IsFile() && path.Base(path.Dir(file.Name)) == ".circleci" &&
+		strings.TrimSuffix(path.Base(file.Name), ext) == "config" && (ext == ".yml" || ext == ".yaml")
+}
+
+func (c *ConfigScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err = yaml.Unmarshal(content.Bytes(), &config); err != nil {
+		return nil, err
+	}
+
+	for name, orb := range config.Orbs {
+		var val = map[string]any{"name": name}
+		if ref, ok := orb.(string); ok { // orbs are referenced as <namespace>/<orb>@<version>
+			var orb, version, _ = strings.Cut(ref, "@")
+			val["orb"], val["version"] = orb, version
+		} else {
+			val["inline"] = true // orbs may also be declared inline
+		}
+		emit("@circleci/orb", val)
+	}
+
+	// images emits a fact for each image the executor runs on
+	var images = func(attr string, name string, executor Executor) {
+		for _, docker := range executor.Docker {
+			emit("@circleci/image", map[string]any{attr: name, "image": docker.Image, "kind": "docker"})
+		}
+		if machine, ok := executor.Machine.(map[string]any); ok && machine["image"] != nil {
+			emit("@circleci/image", map[string]any{attr: name, "image": machine["image"], "kind": "machine"})
+		}
+		if macos, ok := executor.Macos.(map[string]any); ok && macos["xcode"] != nil {
+			emit("@circleci/image", map[string]any{attr: name, "image": macos["xcode"], "kind": "macos"})
+		}
+	}
+
+	for name, executor := range config.Executors {
+		images("executor", name, executor)
+	}
+
+	for name, job := range config.Jobs {
+		var val = map[string]any{"job": name}
+		switch uses := job.Uses.(type) {
+		case string:
+			val["executor"] = uses
+		case map[string]any:
+			val["executor"] = uses["name"]
+		}
+		emit("@circleci/job", val)
+
+		images("job", name, job.Executor)
+	}
+
+	for name, workflow := range config.Workflows {
+		var workflow, ok = workflow.(map[string]any)
+		if !ok {
+			continue // skip the (legacy) version key
+		}
+
+		// jobs are listed either by name, or as a single-key map of name to job parameters
+		var jobs = make([]string, 0)
+		if list, ok := workflow["jobs"].([]any); ok {
+			for _, job := range list {
+				switch job := job.(type) {
+				case string:
+					jobs = append(jobs, job)
+				case map[string]any:
+					for name := range job {
+						jobs = append(jobs, name)
+					}
+				}
+			}
+		}
+		sort.Strings(jobs)
+
+		emit("@circleci/workflow", map[string]any{"workflow": name, "jobs": jobs})
+	}
+
+	return facts, nil
+}
+
+// register the ConfigScanner with scanner registry
+func init() { scanner.Register("circleci/config", &ConfigScanner{}) }
@@ -0,0 +1,204 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ComposeFile represents the subset of a compose file (https://docs.docker.com/compose/compose-file/) we are interested in.
+// Most attributes support both a short (string / list) and a long (map) syntax, and are decoded as-is.
+type ComposeFile struct {
+	Services map[string]struct {
+		Image       string `json:"image"`
+		Build       any    `json:"build"`
+		Ports       []any  `json:"ports"`
+		Volumes     []any  `json:"volumes"`
+		Environment any    `json:"environment"`
+		DependsOn   any    `json:"depends_on"`
+	} `json:"services"`
+}
+
+// matches docker-compose.yml, compose.yaml and overrides like docker-compose.override.yml or compose.prod.yaml
+var composeFileName = regexp.MustCompile(`^(docker-)?compose(\.[\w.-]+)?\.ya?ml$`)
+
+// ComposeScanner implements scanner.Scanner to extract facts from docker compose files.
+type ComposeScanner struct{}
+
+func (d *ComposeScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && composeFileName.MatchString(path.Base(file.Name))
+}
+
+func (d *ComposeScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	var compose ComposeFile
+	if err = yaml.Unmarshal(content.Bytes(), &compose); err != nil {
+		return nil, err
+	}
+
+	for name, svc := range compose.Services {
+		emit("@docker/compose/service", map[string]any{"service": name})
+
+		if svc.Image != "" {
+			var val = map[string]any{"service": name, "image": svc.Image}
+			var image, tag, digest = parseImageRef(svc.Image)
+			val["registry"], val["namespace"], val["repository"] = splitImageName(image)
+			if digest != "" {
+				val["digest"] = digest
+			} else if tag != "" {
+				val["tag"] = tag
+			} else {
+				val["tag"] = "latest" // implicit default tag
+			}
+			emit("@docker/compose/image", val)
+		}
+
+		switch build := svc.Build.(type) {
+		case string:
+			emit("@docker/compose/build", map[string]any{"service": name, "context": build})
+		case map[string]any:
+			var val = map[string]any{"service": name, "context": build["context"]}
+			for _, attr := range []string{"dockerfile", "target"} {
+				if v, ok := build[attr]; ok {
+					val[attr] = v
+				}
+			}
+			emit("@docker/compose/build", val)
+		}
+
+		for _, port := range svc.Ports {
+			var val = composePort(port)
+			val["service"] = name
+			emit("@docker/compose/port", val)
+		}
+
+		for _, volume := range svc.Volumes {
+			var val = composeVolume(volume)
+			val["service"] = name
+			emit("@docker/compose/volume", val)
+		}
+
+		// only the names are emitted, as values might well be secrets
+		var env []string
+		switch environment := svc.Environment.(type) {
+		case []any: // list syntax, as in - KEY=value
+			for _, e := range environment {
+				var key, _, _ = strings.Cut(fmt.Sprint(e), "=")
+				env = append(env, key)
+			}
+		case map[string]any: // map syntax, as in KEY: value
+			for key := range environment {
+				env = append(env, key)
+			}
+		}
+		for _, key := range env {
+			emit("@docker/compose/environment", map[string]any{"service": name, "name": key})
+		}
+
+		switch dependsOn := svc.DependsOn.(type) {
+		case []any:
+			for _, dep := range dependsOn {
+				emit("@docker/compose/depends-on", map[string]any{"service": name, "depends_on": fmt.Sprint(dep)})
+			}
+		case map[string]any: // long syntax, with conditions
+			for dep, opts := range dependsOn {
+				var val = map[string]any{"service": name, "depends_on": dep}
+				if opts, ok := opts.(map[string]any); ok && opts["condition"] != nil {
+					val["condition"] = opts["condition"]
+				}
+				emit("@docker/compose/depends-on", val)
+			}
+		}
+	}
+
+	return facts, nil
+}
+
+// composePort parses a port mapping, either in the short ([[host_ip:]published:]target[/protocol]) or the long syntax
+func composePort(port any) map[string]any {
+	if long, ok := port.(map[string]any); ok {
+		var val = map[string]any{"target": fmt.Sprint(long["target"]), "protocol": "tcp"}
+		if long["published"] != nil {
+			val["published"] = fmt.Sprint(long["published"])
+		}
+		if long["protocol"] != nil {
+			val["protocol"] = long["protocol"]
+		}
+		if long["host_ip"] != nil {
+			val["host_ip"] = long["host_ip"]
+		}
+		return val
+	}
+
+	var spec, protocol, found = strings.Cut(fmt.Sprint(port), "/")
+	if !found {
+		protocol = "tcp"
+	}
+
+	var parts = strings.Split(spec, ":")
+	var val = map[string]any{"target": parts[len(parts)-1], "protocol": protocol}
+	if len(parts) >= 2 {
+		val["published"] = parts[len(parts)-2]
+	}
+	if len(parts) >= 3 {
+		val["host_ip"] = strings.Join(parts[:len(parts)-2], ":")
+	}
+	return val
+}
+
+// composeVolume parses a volume mount, either in the short ([source:]target[:mode]) or the long syntax
+func composeVolume(volume any) map[string]any {
+	if long, ok := volume.(map[string]any); ok {
+		var val = map[string]any{"type": long["type"], "target": long["target"]}
+		if long["source"] != nil {
+			val["source"] = long["source"]
+		}
+		if long["read_only"] != nil {
+			val["read_only"] = long["read_only"]
+		}
+		return val
+	}
+
+	var parts = strings.Split(fmt.Sprint(volume), ":")
+	if len(parts) == 1 {
+		return map[string]any{"type": "volume", "target": parts[0]} // anonymous volume
+	}
+
+	var val = map[string]any{"source": parts[0], "target": parts[1], "type": "volume"}
+	if strings.HasPrefix(parts[0], ".") || strings.HasPrefix(parts[0], "/") || strings.HasPrefix(parts[0], "~") {
+		val["type"] = "bind" // paths are bind-mounted; everything else refers to a named volume
+	}
+	if len(parts) > 2 {
+		val["read_only"] = false
+		for _, mode := range strings.Split(parts[2], ",") {
+			if mode == "ro" {
+				val["read_only"] = true
+			}
+		}
+	}
+	return val
+}
+
+// register the ComposeScanner with scanner registry
+func init() { scanner.Register("docker/compose", &ComposeScanner{}) }
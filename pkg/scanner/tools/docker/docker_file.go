@@ -3,24 +3,59 @@ package docker
 import (
 	"bytes"
 	"context"
-	"errors"
+	"encoding/json"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/mergestat/kyc/pkg/scanner"
 	utils "github.com/mergestat/kyc/pkg/tree-sitter-utils"
 	sitter "github.com/smacker/go-tree-sitter"
-	"github.com/smacker/go-tree-sitter/dockerfile"
 	"golang.org/x/sync/errgroup"
 	"io"
+	"path"
+	"regexp"
 	"strings"
 )
 
 type extractFn func(context.Context, *sitter.Tree, []byte, chan<- scanner.Fact) error
 
+var (
+	// matches Dockerfile and Containerfile, optionally followed by a variant, as in Dockerfile.dev or Containerfile.prod
+	dockerfileName = regexp.MustCompile(`^(?:docker|container)file(?:\.([\w.-]+))?$`)
+
+	// matches Dockerfile and Containerfile preceded by a variant, as in api.Dockerfile or api-Dockerfile
+	dockerfileSuffix = regexp.MustCompile(`^[\w.-]+[.-](?:docker|container)file$`)
+
+	// matches references to variables, as in $VAR, ${VAR} or ${VAR:-default}
+	variableRef = regexp.MustCompile(`\$(?:\{[^}]*\}|\w+)`)
+
+	// extensions of source (and other) files that are named like Dockerfile variants, but are not Dockerfiles themselves
+	sourceExt = map[string]bool{
+		"go": true, "py": true, "js": true, "jsx": true, "mjs": true, "cjs": true, "ts": true, "tsx": true, "rb": true,
+		"rs": true, "java": true, "kt": true, "kts": true, "scala": true, "groovy": true, "gradle": true, "cs": true,
+		"fs": true, "c": true, "h": true, "cc": true, "cpp": true, "hpp": true, "swift": true, "php": true, "pl": true,
+		"lua": true, "dart": true, "ex": true, "exs": true, "sh": true, "bash": true, "ps1": true, "bat": true,
+		"md": true, "rst": true, "txt": true, "json": true, "yml": true, "yaml": true, "toml": true, "xml": true,
+		"dockerignore": true, "bak": true, "orig": true, "swp": true,
+	}
+)
+
 // DockerfileScanner implements scanner.Scanner to extract facts from Dockerfiles.
 type DockerfileScanner struct{}
 
 func (d *DockerfileScanner) Supports(file *object.File) bool {
-	return file.Mode.IsFile() && strings.HasSuffix(file.Name, "Dockerfile")
+	if !file.Mode.IsFile() {
+		return false
+	}
+
+	// matches Dockerfile, Dockerfile.dev, api.Dockerfile, api-Dockerfile, Containerfile, Containerfile.prod, etc.
+	var name = strings.ToLower(path.Base(file.Name))
+	if match := dockerfileName.FindStringSubmatch(name); match != nil {
+		var variant = match[1]
+		if i := strings.LastIndex(variant, "."); i >= 0 {
+			variant = variant[i+1:]
+		}
+		return !sourceExt[variant] // but not dockerfile.go, Dockerfile.tsx, etc.
+	}
+	return dockerfileSuffix.MatchString(name)
 }
 
 func (d *DockerfileScanner) Scan(ctx context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
@@ -38,11 +73,8 @@ func (d *DockerfileScanner) Scan(ctx context.Context, file *object.File, _ ...st
 		return nil, err
 	}
 
-	var parser = sitter.NewParser()
-	parser.SetLanguage(dockerfile.GetLanguage())
-
 	var tree *sitter.Tree
-	if tree, err = parser.ParseCtx(context.Background(), nil, content.Bytes()); err != nil {
+	if tree, err = utils.Parse(ctx, utils.GetLanguage("dockerfile"), content.Bytes()); err != nil {
 		return nil, err
 	}
 	defer tree.Close()
@@ -50,9 +82,18 @@ func (d *DockerfileScanner) Scan(ctx context.Context, file *object.File, _ ...st
 	var result = make(chan scanner.Fact)
 	g, ctx := errgroup.WithContext(ctx)
 
-	var extractors = []extractFn{directiveFrom}
+	var extractors = []extractFn{
+		directiveFrom, directiveRun, directiveEnv, directiveArg, directiveExpose,
+		directiveUser, directiveLabel, directiveCopy, directiveEntrypoint,
+	}
 	for _, ext := range extractors {
-		g.Go(func() error { return ext(ctx, tree.Copy(), content.Bytes(), result) })
+		ext := ext
+		g.Go(func() error {
+			// trees are not safe for concurrent use; each extractor gets its own (cheap, shallow) copy
+			var tree = tree.Copy()
+			defer tree.Close()
+			return ext(ctx, tree, content.Bytes(), result)
+		})
 	}
 
 	go func() { err = g.Wait(); close(result) }() // close the channel after all goroutines have returned
@@ -65,44 +106,425 @@ func (d *DockerfileScanner) Scan(ctx context.Context, file *object.File, _ ...st
 
 // extractor function to parse FROM directives
 func directiveFrom(ctx context.Context, tree *sitter.Tree, content []byte, c chan<- scanner.Fact) error {
-	// predicate functions for use with utils.Find()
-	isFromInstruction := func(node *sitter.Node) bool { return node.IsNamed() && node.Type() == "from_instruction" }
+	// predicate function for use with utils.Find()
 	isImageSpec := func(node *sitter.Node) bool { return node.IsNamed() && node.Type() == "image_spec" }
 
-	var fromNodes = utils.Find(tree.RootNode(), isFromInstruction) // find all FROM nodes in the tree
-	for _, from := range fromNodes {
-		var imageSpec = utils.Find(from, isImageSpec)
+	// only ARGs declared before the first FROM are in scope for FROM directives
+	var args = make(map[string]string)
+	for _, arg := range instructions(tree, content, "arg_instruction") {
+		if def := arg.node.ChildByFieldName("default"); arg.stage.index < 0 && def != nil {
+			args[text(arg.node.ChildByFieldName("name"), content)] = text(def, content)
+		}
+	}
+
+	// aliases of all build stages declared so far, mapped to their index
+	var stages = make(map[string]int)
+
+	for _, from := range instructions(tree, content, "from_instruction") {
+		var imageSpec = utils.Find(from.node, isImageSpec)
 		if len(imageSpec) != 1 {
-			return errors.New("malformed dockerfile")
+			continue // malformed instruction; skipped rather than failing the whole file
+		}
+
+		var raw = imageSpec[0].Content(content)
+		var ref = expand(raw, args)
+		var name, tag, digest = parseImageRef(ref)
+
+		// FROM <stage> refers to an earlier build stage, rather than an external image
+		if index, ok := stages[strings.ToLower(ref)]; ok {
+			var val = from.stage.tag(map[string]any{"depends_on": ref, "depends_on_stage": index})
+			if err := emit(ctx, c, "@docker/dockerfile/stage-dependency", val); err != nil {
+				return err
+			}
+			if from.stage.alias != "" {
+				stages[strings.ToLower(from.stage.alias)] = from.stage.index
+			}
+			continue
 		}
 
-		var spec = imageSpec[0]
-		name, digest, tag := spec.ChildByFieldName("name"), spec.ChildByFieldName("digest"), spec.ChildByFieldName("tag")
-		if name == nil || (digest == nil && tag == nil) {
-			return errors.New("malformed dockerfile")
+		if name == "" {
+			continue // malformed instruction; skipped rather than failing the whole file
 		}
 
-		var val = map[string]any{"name": name.Content(content)}
-		if digest != nil {
-			val["digest"] = digest.Content(content)[1:]
-		} else {
-			val["tag"] = tag.Content(content)[1:]
+		var val = map[string]any{"name": name, "stage": from.stage.index}
+		if name != "scratch" { // scratch is a reserved, empty image and not pulled from any registry
+			val["registry"], val["namespace"], val["repository"] = splitImageName(name)
+			if digest != "" {
+				val["digest"] = digest // when pinned by digest, any tag is informational only
+			}
+			if tag != "" {
+				val["tag"] = tag
+			} else if digest == "" {
+				val["tag"] = "latest" // implicit default tag
+			}
 		}
 
-		if as := from.ChildByFieldName("as"); as != nil {
-			val["alias"] = as.Content(content)
+		if platform, ok := params(from.node, content)["platform"]; ok {
+			val["platform"] = expand(platform, args)
+		}
+
+		if ref != raw {
+			val["raw"] = raw // image reference before ARG substitution
+		}
+
+		if from.stage.alias != "" {
+			val["alias"] = from.stage.alias
+			stages[strings.ToLower(from.stage.alias)] = from.stage.index
 		}
 
 		// emit fact! or not if the ctx is cancelled :)
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case c <- scanner.Fact{Key: "@docker/dockerfile/base-image", Value: val}:
+		if err := emit(ctx, c, "@docker/dockerfile/base-image", val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractor function to parse RUN directives
+func directiveRun(ctx context.Context, tree *sitter.Tree, content []byte, c chan<- scanner.Fact) error {
+	for _, run := range instructions(tree, content, "run_instruction") {
+		var val = run.stage.tag(command(run.node, content))
+		if flags := params(run.node, content); len(flags) > 0 {
+			val["flags"] = flags
+		}
+
+		if err := emit(ctx, c, "@docker/dockerfile/run", val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractor function to parse ENV directives
+func directiveEnv(ctx context.Context, tree *sitter.Tree, content []byte, c chan<- scanner.Fact) error {
+	for _, env := range instructions(tree, content, "env_instruction") {
+		for _, pair := range children(env.node, "env_pair") {
+			var val = env.stage.tag(map[string]any{"name": text(pair.ChildByFieldName("name"), content)})
+			if value := pair.ChildByFieldName("value"); value != nil {
+				val["value"] = text(value, content)
+			}
+
+			if err := emit(ctx, c, "@docker/dockerfile/env", val); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
+}
+
+// extractor function to parse ARG directives
+func directiveArg(ctx context.Context, tree *sitter.Tree, content []byte, c chan<- scanner.Fact) error {
+	for _, arg := range instructions(tree, content, "arg_instruction") {
+		var val = arg.stage.tag(map[string]any{"name": text(arg.node.ChildByFieldName("name"), content)})
+		if def := arg.node.ChildByFieldName("default"); def != nil {
+			val["default"] = text(def, content)
+		}
+
+		if err := emit(ctx, c, "@docker/dockerfile/arg", val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractor function to parse EXPOSE directives
+func directiveExpose(ctx context.Context, tree *sitter.Tree, content []byte, c chan<- scanner.Fact) error {
+	for _, expose := range instructions(tree, content, "expose_instruction") {
+		for _, port := range children(expose.node, "expose_port") {
+			// ports are specified as <port>[/<protocol>], with tcp being the default protocol
+			var number, protocol, found = strings.Cut(port.Content(content), "/")
+			if !found {
+				protocol = "tcp"
+			}
 
+			var val = expose.stage.tag(map[string]any{"port": number, "protocol": strings.ToLower(protocol)})
+			if err := emit(ctx, c, "@docker/dockerfile/expose", val); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// extractor function to parse USER directives
+func directiveUser(ctx context.Context, tree *sitter.Tree, content []byte, c chan<- scanner.Fact) error {
+	for _, user := range instructions(tree, content, "user_instruction") {
+		var val = user.stage.tag(map[string]any{"user": text(user.node.ChildByFieldName("user"), content)})
+		if group := user.node.ChildByFieldName("group"); group != nil {
+			val["group"] = text(group, content)
+		}
+
+		if err := emit(ctx, c, "@docker/dockerfile/user", val); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// extractor function to parse LABEL directives
+func directiveLabel(ctx context.Context, tree *sitter.Tree, content []byte, c chan<- scanner.Fact) error {
+	for _, label := range instructions(tree, content, "label_instruction") {
+		for _, pair := range children(label.node, "label_pair") {
+			key, value := pair.ChildByFieldName("key"), pair.ChildByFieldName("value")
+
+			var val = label.stage.tag(map[string]any{"key": text(key, content), "value": text(value, content)})
+			if err := emit(ctx, c, "@docker/dockerfile/label", val); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// extractor function to parse COPY directives
+func directiveCopy(ctx context.Context, tree *sitter.Tree, content []byte, c chan<- scanner.Fact) error {
+	for _, cp := range instructions(tree, content, "copy_instruction") {
+		var paths = copyPaths(cp.node, content)
+		if len(paths) < 2 {
+			continue // malformed instruction; skipped rather than failing the whole file
+		}
+
+		// the last path is always the destination; all the others are sources
+		var val = cp.stage.tag(map[string]any{"sources": paths[:len(paths)-1], "destination": paths[len(paths)-1]})
+		if flags := params(cp.node, content); len(flags) > 0 {
+			val["flags"] = flags
+		}
+
+		if err := emit(ctx, c, "@docker/dockerfile/copy", val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractor function to parse ENTRYPOINT directives
+func directiveEntrypoint(ctx context.Context, tree *sitter.Tree, content []byte, c chan<- scanner.Fact) error {
+	for _, entrypoint := range instructions(tree, content, "entrypoint_instruction") {
+		var val = entrypoint.stage.tag(command(entrypoint.node, content))
+		if err := emit(ctx, c, "@docker/dockerfile/entrypoint", val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyPaths returns the paths of a COPY instruction, written either in the shell form (COPY a b /dest/)
+// or in the json form (COPY ["a b", "/dest/"]), the latter being required for paths containing whitespace.
+func copyPaths(node *sitter.Node, content []byte) []string {
+	// newer versions of the grammar parse the json form into a json_string_array (or string_array) node
+	for _, typ := range []string{"json_string_array", "string_array"} {
+		if arrays := children(node, typ); len(arrays) > 0 {
+			var paths []string
+			for _, str := range children(arrays[0], "double_quoted_string") {
+				paths = append(paths, text(str, content))
+			}
+			return paths
+		}
+	}
+
+	var nodes = children(node, "path")
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	// older versions split the json form on whitespace, into path nodes; so we decode the source text spanning all of them
+	var raw = content[nodes[0].StartByte():nodes[len(nodes)-1].EndByte()]
+	if bytes.HasPrefix(raw, []byte("[")) {
+		var paths []string
+		if err := json.Unmarshal(raw, &paths); err != nil {
+			return nil
+		}
+		return paths
+	}
+
+	var paths []string
+	for _, path := range nodes {
+		paths = append(paths, path.Content(content))
+	}
+	return paths
+}
+
+// stage identifies the build stage (started by a FROM directive) that an instruction belongs to
+type stage struct {
+	index int    // zero-based index of the stage; -1 for instructions that appear before the first FROM
+	alias string // name of the stage, as declared using FROM ... AS <alias>
+}
+
+// tag adds the stage information to the given fact value
+func (s stage) tag(val map[string]any) map[string]any {
+	if s.index >= 0 {
+		val["stage"] = s.index
+		if s.alias != "" {
+			val["stage_alias"] = s.alias
+		}
+	}
+	return val
+}
+
+type instruction struct {
+	node  *sitter.Node
+	stage stage
+}
+
+// instructions returns all (top-level) instructions of the given type, along with the build stage they belong to
+func instructions(tree *sitter.Tree, content []byte, typ string) []instruction {
+	var result []instruction
+	var current = stage{index: -1}
+
+	var root = tree.RootNode()
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		var node = root.NamedChild(i)
+		if node.Type() == "from_instruction" {
+			current = stage{index: current.index + 1}
+			if as := node.ChildByFieldName("as"); as != nil {
+				current.alias = as.Content(content)
+			}
+		}
+
+		if node.Type() == typ {
+			result = append(result, instruction{node: node, stage: current})
+		}
+	}
+
+	return result
+}
+
+// children returns all named children of the node with the given type
+func children(node *sitter.Node, typ string) []*sitter.Node {
+	var result []*sitter.Node
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == typ {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// params returns the flags (such as --from=builder or --platform=linux/amd64) passed to the instruction
+func params(node *sitter.Node, content []byte) map[string]string {
+	var result = make(map[string]string)
+	for _, param := range children(node, "param") {
+		var name, value, _ = strings.Cut(strings.TrimPrefix(param.Content(content), "--"), "=")
+		result[name] = value
+	}
+	return result
+}
+
+// command returns the command used by a RUN or ENTRYPOINT instruction, either in shell or exec form
+func command(node *sitter.Node, content []byte) map[string]any {
+	if array := children(node, "string_array"); len(array) == 1 {
+		var args = make([]string, 0)
+		for i := 0; i < int(array[0].NamedChildCount()); i++ {
+			args = append(args, text(array[0].NamedChild(i), content))
+		}
+		return map[string]any{"exec": args}
+	}
+
+	var shell string
+	if cmd := children(node, "shell_command"); len(cmd) == 1 {
+		// join lines continued using a trailing backslash
+		var lines = strings.Split(cmd[0].Content(content), "\\\n")
+		for i := range lines {
+			lines[i] = strings.TrimSpace(lines[i])
+		}
+		shell = strings.Join(lines, " ")
+	}
+	return map[string]any{"shell": shell}
+}
+
+// text returns the (unquoted) content of a string node
+func text(node *sitter.Node, content []byte) string {
+	if node == nil {
+		return ""
+	}
+
+	var str = node.Content(content)
+	switch node.Type() {
+	case "double_quoted_string":
+		var unquoted string
+		if err := json.Unmarshal([]byte(str), &unquoted); err == nil {
+			return unquoted
+		}
+		return strings.Trim(str, `"`)
+	case "single_quoted_string":
+		return strings.Trim(str, "'")
+	}
+	return str
+}
+
+// expand substitutes references to the given args (in $VAR, ${VAR}, ${VAR:-default} or ${VAR:+alternate} form)
+// in the string. References to unknown args are left exactly as written.
+func expand(str string, args map[string]string) string {
+	return variableRef.ReplaceAllStringFunc(str, func(match string) string {
+		var ref = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(match, "$"), "{"), "}")
+
+		var name, modifier, word = ref, "", ""
+		if i := strings.Index(ref, ":"); i > 0 && len(ref) > i+1 {
+			name, modifier, word = ref[:i], ref[i:i+2], ref[i+2:]
+		}
+
+		var value, ok = args[name]
+		switch {
+		case modifier == ":-" && value == "":
+			return word
+		case modifier == ":+":
+			if value != "" {
+				return word
+			}
+			return ""
+		case !ok:
+			return match
+		}
+		return value
+	})
+}
+
+// parseImageRef splits an image reference of the form name[:tag][@digest] into its components
+func parseImageRef(ref string) (name, tag, digest string) {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref, digest = ref[:i], ref[i+1:]
+	}
+
+	// a colon that appears before the last slash separates the registry host from the port, not the tag
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref, tag = ref[:i], ref[i+1:]
+	}
+
+	return ref, tag, digest
+}
+
+// splitImageName splits an image name into the registry, namespace and repository components,
+// following the same normalization rules as docker, such that "ubuntu" is split into "docker.io", "library" and "ubuntu".
+func splitImageName(name string) (registry, namespace, repository string) {
+	registry = "docker.io"
+
+	// the first component is a registry only if it looks like a hostname (with a dot or port), or is localhost
+	if i := strings.Index(name, "/"); i >= 0 {
+		if host := name[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			registry, name = host, name[i+1:]
+		}
+	}
+
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return registry, name[:i], name[i+1:]
+	}
+
+	if registry == "docker.io" {
+		return registry, "library", name // official images on docker hub
+	}
+	return registry, "", name
+}
+
+// emit sends the fact to the channel, unless the context is cancelled
+func emit(ctx context.Context, c chan<- scanner.Fact, key string, val any) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case c <- scanner.Fact{Key: key, Value: val}:
+		return nil
+	}
+}
+
 // register the DockerfileScanner with scanner registry
 func init() { scanner.Register("docker/dockerfile", &DockerfileScanner{}) }
@@ -0,0 +1,41 @@
+package docker
+
+import (
+	"github.com/mergestat/kyc/pkg/scanner/scannertest"
+	"testing"
+)
+
+func TestDockerfileScanner_Supports(t *testing.T) {
+	var cases = []struct {
+		name string
+		want bool
+	}{
+		{name: "Dockerfile", want: true},
+		{name: "build/Dockerfile", want: true},
+		{name: "dockerfile", want: true},
+		{name: "Containerfile", want: true},
+		{name: "Dockerfile.dev", want: true},
+		{name: "Dockerfile.ubuntu-22.04", want: true},
+		{name: "Containerfile.prod", want: true},
+		{name: "api.Dockerfile", want: true},
+		{name: "api-Dockerfile", want: true},
+		{name: "build.dockerfile", want: true},
+
+		{name: "dockerfile.go", want: false},
+		{name: "dockerfile.kt", want: false},
+		{name: "Dockerfile.tsx", want: false},
+		{name: "Dockerfile.md", want: false},
+		{name: "Dockerfile.dockerignore", want: false},
+		{name: "dockerfile_test.go", want: false},
+		{name: "Dockerfiles", want: false},
+		{name: "docker-compose.yml", want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := (&DockerfileScanner{}).Supports(scannertest.File(tc.name, "")); got != tc.want {
+				t.Errorf("Supports(%q) = %v, want %v", tc.name, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Workflow represents the subset of a GitHub Actions workflow file we are interested in.
+type Workflow struct {
+	Name string `json:"name"`
+
+	// yaml 1.1 parses an unquoted on key as boolean true, and so
+	// the triggers would be found under "true" in most workflow files
+	On     any `json:"on"`
+	OnTrue any `json:"true"`
+
+	Jobs map[string]struct {
+		Name   string `json:"name"`
+		RunsOn any    `json:"runs-on"`
+		Uses   string `json:"uses"` // for jobs calling reusable workflows
+		Steps  []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			Uses string `json:"uses"`
+		} `json:"steps"`
+	} `json:"jobs"`
+}
+
+// matches commit SHAs, the only immutable kind of ref an action can be pinned to
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// WorkflowScanner implements scanner.Scanner to extract facts from GitHub Actions workflow files.
+type WorkflowScanner struct{}
+
+func (w *WorkflowScanner) Supports(file *object.File) bool {
+	var ext = path.Ext(file.Name)
+	return file.Mode.IsFile() && path.Dir(file.Name) == ".github/workflows" && (ext == ".yml" || ext == ".yaml")
+}
+
+func (w *WorkflowScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	var workflow Workflow
+	if err = yaml.Unmarshal(content.Bytes(), &workflow); err != nil {
+		return nil, err
+	}
+
+	emit("@github/workflow", map[string]any{"name": workflow.Name})
+
+	var on = workflow.On
+	if on == nil {
+		on = workflow.OnTrue
+	}
+	for _, event := range strs(on) {
+		emit("@github/workflow/trigger", map[string]any{"event": event})
+	}
+
+	for _, id := range sortedKeys(workflow.Jobs) {
+		var job = workflow.Jobs[id]
+		var val = map[string]any{"job": id, "name": job.Name}
+		if job.RunsOn != nil {
+			// runs-on is either a label, a list of labels or a map of runner group and labels
+			var labels []string
+			if group, ok := job.RunsOn.(map[string]any); ok {
+				labels, val["runner_group"] = strs(group["labels"]), group["group"]
+			} else {
+				labels = strs(job.RunsOn)
+			}
+			val["runs_on"] = labels
+		}
+		emit("@github/workflow/job", val)
+
+		if job.Uses != "" {
+			var val = parseUses(job.Uses, "workflow")
+			val["job"] = id
+			emit("@github/workflow/uses", val)
+		}
+
+		for i, step := range job.Steps {
+			if step.Uses == "" {
+				continue
+			}
+
+			var val = parseUses(step.Uses, "action")
+			val["job"], val["step"] = id, i
+			if step.ID != "" {
+				val["step_id"] = step.ID
+			}
+			if step.Name != "" {
+				val["step_name"] = step.Name
+			}
+			emit("@github/workflow/uses", val)
+		}
+	}
+
+	return facts, nil
+}
+
+// parseUses splits a uses: reference, such as actions/checkout@v3, into its components
+func parseUses(uses string, kind string) map[string]any {
+	var val = map[string]any{"uses": uses}
+
+	switch {
+	case strings.HasPrefix(uses, "./"):
+		val["kind"], val["path"] = "local", uses
+	case strings.HasPrefix(uses, "docker://"):
+		val["kind"], val["image"] = "docker", strings.TrimPrefix(uses, "docker://")
+	default:
+		// {owner}/{repo}[/{path}]@{ref}
+		var name, ref, _ = strings.Cut(uses, "@")
+		var parts = strings.SplitN(name, "/", 3)
+
+		val["kind"], val["ref"], val["pinned"] = kind, ref, commitSHA.MatchString(ref)
+		val["owner"] = parts[0]
+		if len(parts) > 1 {
+			val["repo"] = parts[1]
+		}
+		if len(parts) > 2 {
+			val["path"] = parts[2]
+		}
+	}
+
+	return val
+}
+
+// strs converts a string, a list of strings or the keys of a map into a (sorted, for maps) list of strings
+func strs(value any) []string {
+	var result = make([]string, 0)
+	switch value := value.(type) {
+	case string:
+		result = append(result, value)
+	case []any:
+		for _, v := range value {
+			result = append(result, fmt.Sprint(v))
+		}
+	case map[string]any:
+		for k := range value {
+			result = append(result, k)
+		}
+		sort.Strings(result)
+	}
+	return result
+}
+
+// sortedKeys returns the keys of the map in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	var keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// register the WorkflowScanner with scanner registry
+func init() { scanner.Register("github/workflow", &WorkflowScanner{}) }
@@ -0,0 +1,174 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// top-level keywords in .gitlab-ci.yml that do not define jobs
+// see: https://docs.gitlab.com/ee/ci/yaml/#global-keywords
+var keywords = map[string]bool{
+	"default": true, "include": true, "stages": true, "variables": true, "workflow": true, "spec": true,
+	"image": true, "services": true, "cache": true, "before_script": true, "after_script": true,
+}
+
+// CIScanner implements scanner.Scanner to extract facts from GitLab CI/CD configuration files.
+type CIScanner struct{}
+
+func (c *CIScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == ".gitlab-ci.yml"
+}
+
+func (c *CIScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	var config map[string]any
+	if err = yaml.Unmarshal(content.Bytes(), &config); err != nil {
+		return nil, err
+	}
+
+	if stages, ok := config["stages"].([]any); ok {
+		for i, stage := range stages {
+			emit("@gitlab/ci/stage", map[string]any{"stage": fmt.Sprint(stage), "index": i})
+		}
+	}
+
+	for _, include := range includes(config["include"]) {
+		emit("@gitlab/ci/include", include)
+	}
+
+	// images (and services) declared globally apply to all jobs that do not declare their own
+	var images = func(job string, spec map[string]any) {
+		if spec == nil {
+			return
+		}
+		if image := imageName(spec["image"]); image != "" {
+			emit("@gitlab/ci/image", map[string]any{"job": job, "image": image, "service": false})
+		}
+		if services, ok := spec["services"].([]any); ok {
+			for _, service := range services {
+				if image := imageName(service); image != "" {
+					emit("@gitlab/ci/image", map[string]any{"job": job, "image": image, "service": true})
+				}
+			}
+		}
+	}
+	images("", config)
+	if def, ok := config["default"].(map[string]any); ok {
+		images("", def)
+	}
+
+	var names = make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var job, ok = config[name].(map[string]any)
+		if !ok || keywords[name] {
+			continue
+		}
+
+		var val = map[string]any{"job": name, "stage": "test", "hidden": strings.HasPrefix(name, ".")}
+		if stage, ok := job["stage"].(string); ok {
+			val["stage"] = stage
+		}
+		switch extends := job["extends"].(type) {
+		case string:
+			val["extends"] = []string{extends}
+		case []any:
+			var list []string
+			for _, e := range extends {
+				list = append(list, fmt.Sprint(e))
+			}
+			val["extends"] = list
+		}
+		emit("@gitlab/ci/job", val)
+
+		images(name, job)
+	}
+
+	return facts, nil
+}
+
+// includes normalizes all forms of the include keyword into a list of fact values
+func includes(include any) []map[string]any {
+	var result []map[string]any
+	switch include := include.(type) {
+	case string:
+		// a plain string is a remote include if it's a url, else it's a local include
+		if strings.HasPrefix(include, "http://") || strings.HasPrefix(include, "https://") {
+			result = append(result, map[string]any{"kind": "remote", "remote": include})
+		} else {
+			result = append(result, map[string]any{"kind": "local", "local": include})
+		}
+	case []any:
+		for _, inc := range include {
+			result = append(result, includes(inc)...)
+		}
+	case map[string]any:
+		for _, kind := range []string{"local", "remote", "template", "component"} {
+			if value, ok := include[kind]; ok {
+				result = append(result, map[string]any{"kind": kind, kind: value})
+			}
+		}
+
+		// project includes may list one or more files, from the given ref
+		if project, ok := include["project"]; ok {
+			var files []any
+			switch file := include["file"].(type) {
+			case string:
+				files = []any{file}
+			case []any:
+				files = file
+			}
+
+			for _, file := range files {
+				var val = map[string]any{"kind": "project", "project": project, "file": file}
+				if ref, ok := include["ref"]; ok {
+					val["ref"] = ref
+				}
+				result = append(result, val)
+			}
+		}
+	}
+	return result
+}
+
+// imageName returns the name of the image, which is either specified as a string or as a map with a name key
+func imageName(image any) string {
+	switch image := image.(type) {
+	case string:
+		return image
+	case map[string]any:
+		if name, ok := image["name"].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// register the CIScanner with scanner registry
+func init() { scanner.Register("gitlab/ci", &CIScanner{}) }
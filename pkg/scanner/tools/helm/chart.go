@@ -0,0 +1,88 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path"
+)
+
+// Dependency represents a chart dependency, as declared in Chart.yaml (or requirements.yaml for apiVersion v1 charts)
+type Dependency struct {
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version"`
+	Repository string `yaml:"repository"`
+	Condition  string `yaml:"condition"`
+	Alias      string `yaml:"alias"`
+}
+
+// Chart represents the subset of Chart.yaml we are interested in.
+type Chart struct {
+	APIVersion   string       `yaml:"apiVersion"`
+	Name         string       `yaml:"name"`
+	Version      string       `yaml:"version"`
+	AppVersion   string       `yaml:"appVersion"`
+	Type         string       `yaml:"type"`
+	Dependencies []Dependency `yaml:"dependencies"`
+}
+
+// ChartScanner implements scanner.Scanner to extract facts from Chart.yaml and requirements.yaml files.
+type ChartScanner struct{}
+
+func (c *ChartScanner) Supports(file *object.File) bool {
+	var name = path.Base(file.Name)
+	return file.Mode.IsFile() && (name == "Chart.yaml" || name == "requirements.yaml")
+}
+
+func (c *ChartScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	var chart Chart
+	if err = yaml.Unmarshal(content.Bytes(), &chart); err != nil {
+		return nil, err
+	}
+
+	// requirements.yaml only lists dependencies; the chart itself is described by the Chart.yaml next to it
+	if path.Base(file.Name) == "Chart.yaml" {
+		var val = map[string]any{
+			"name": chart.Name, "version": chart.Version, "app_version": chart.AppVersion,
+			"api_version": chart.APIVersion, "type": chart.Type,
+		}
+		if chart.Type == "" {
+			val["type"] = "application" // default chart type
+		}
+		facts = append(facts, scanner.Fact{Key: "@helm/chart", Value: val})
+	}
+
+	// for each dependency, emit a fact
+	for _, dep := range chart.Dependencies {
+		var val = map[string]any{"name": dep.Name, "version": dep.Version, "repository": dep.Repository}
+		if dep.Alias != "" {
+			val["alias"] = dep.Alias
+		}
+		if dep.Condition != "" {
+			val["condition"] = dep.Condition
+		}
+		facts = append(facts, scanner.Fact{Key: "@helm/chart/dependency", Value: val})
+	}
+
+	return facts, nil
+}
+
+// register the ChartScanner with scanner registry
+func init() { scanner.Register("helm/chart", &ChartScanner{}) }
@@ -0,0 +1,60 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path"
+)
+
+// ChartLock represents the content of Chart.lock (or requirements.lock for apiVersion v1 charts)
+type ChartLock struct {
+	Digest       string `yaml:"digest"`
+	Dependencies []struct {
+		Name       string `yaml:"name"`
+		Version    string `yaml:"version"`
+		Repository string `yaml:"repository"`
+	} `yaml:"dependencies"`
+}
+
+// ChartLockScanner implements scanner.Scanner to extract facts from Chart.lock and requirements.lock files.
+type ChartLockScanner struct{}
+
+func (c *ChartLockScanner) Supports(file *object.File) bool {
+	var name = path.Base(file.Name)
+	return file.Mode.IsFile() && (name == "Chart.lock" || name == "requirements.lock")
+}
+
+func (c *ChartLockScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	var lock ChartLock
+	if err = yaml.Unmarshal(content.Bytes(), &lock); err != nil {
+		return nil, err
+	}
+
+	for _, dep := range lock.Dependencies {
+		var val = map[string]any{"name": dep.Name, "version": dep.Version, "repository": dep.Repository}
+		facts = append(facts, scanner.Fact{Key: "@helm/chart/dependency-locked", Value: val})
+	}
+
+	return facts, nil
+}
+
+// register the ChartLockScanner with scanner registry
+func init() { scanner.Register("helm/chart-lock", &ChartLockScanner{}) }
@@ -0,0 +1,153 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// matches values.yaml, as well as environment specific variants like values-prod.yaml or values.staging.yml
+var valuesFileName = regexp.MustCompile(`^values([.-][\w.-]+)?\.ya?ml$`)
+
+// ValuesScanner implements scanner.Scanner to extract image references from a chart's values.yaml
+type ValuesScanner struct{}
+
+func (v *ValuesScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && valuesFileName.MatchString(path.Base(file.Name))
+}
+
+func (v *ValuesScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	// values are decoded into yaml nodes (rather than plain values) to retain the original
+	// text of scalars, so that an unquoted tag like 1.10 isn't turned into the number 1.1
+	var doc yaml.Node
+	if err = yaml.Unmarshal(content.Bytes(), &doc); err != nil {
+		return nil, err
+	}
+
+	// walk recursively visits all values, looking for anything that looks like an image reference
+	var walk func(string, *yaml.Node)
+	walk = func(key string, node *yaml.Node) {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, child := range node.Content {
+				walk(key, child)
+			}
+		case yaml.AliasNode:
+			walk(key, node.Alias)
+		case yaml.MappingNode:
+			var value = mapping(node)
+			if isImageKey(key) {
+				if val := imageFromMap(value); val != nil {
+					val["path"] = key
+					facts = append(facts, scanner.Fact{Key: "@helm/values/image", Value: val})
+					return
+				}
+			}
+
+			// visit keys in a stable order
+			var keys = make([]string, 0, len(value))
+			for k := range value {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				walk(strings.TrimPrefix(key+"."+k, "."), value[k])
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				walk(fmt.Sprintf("%s[%d]", key, i), item)
+			}
+		case yaml.ScalarNode:
+			if isImageKey(key) && node.Tag == "!!str" && node.Value != "" {
+				facts = append(facts, scanner.Fact{Key: "@helm/values/image", Value: map[string]any{"path": key, "image": node.Value}})
+			}
+		}
+	}
+	walk("", &doc)
+
+	return facts, nil
+}
+
+// isImageKey returns true if the last component of the key path names an image (such as image or sidecarImage)
+func isImageKey(key string) bool {
+	if i := strings.LastIndexAny(key, ".]"); i >= 0 {
+		key = key[i+1:]
+	}
+	return key == "image" || strings.HasSuffix(key, "Image")
+}
+
+// imageFromMap builds an image reference from its components, as commonly laid out in values.yaml like:
+//
+//	image:
+//	  registry: docker.io
+//	  repository: bitnami/redis
+//	  tag: 7.0.11
+func imageFromMap(value map[string]*yaml.Node) map[string]any {
+	var repository = scalar(value["repository"])
+	if repository == "" {
+		if repository = scalar(value["name"]); repository == "" {
+			return nil
+		}
+	}
+
+	var image = repository
+	var val = map[string]any{"repository": repository}
+	if registry := scalar(value["registry"]); registry != "" {
+		image, val["registry"] = registry+"/"+image, registry
+	}
+	if tag := scalar(value["tag"]); tag != "" {
+		image, val["tag"] = image+":"+tag, tag
+	}
+	if digest := scalar(value["digest"]); digest != "" {
+		image, val["digest"] = image+"@"+digest, digest
+	}
+
+	val["image"] = image
+	return val
+}
+
+// mapping returns the key-value pairs of a yaml mapping node, indexed by the key's text
+func mapping(node *yaml.Node) map[string]*yaml.Node {
+	var value = make(map[string]*yaml.Node, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value[node.Content[i].Value] = node.Content[i+1]
+	}
+	return value
+}
+
+// scalar returns the original text of a (non-null) scalar node, or an empty string for anything else
+func scalar(node *yaml.Node) string {
+	if node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node == nil || node.Kind != yaml.ScalarNode || node.Tag == "!!null" {
+		return ""
+	}
+	return node.Value
+}
+
+// register the ValuesScanner with scanner registry
+func init() { scanner.Register("helm/values", &ValuesScanner{}) }
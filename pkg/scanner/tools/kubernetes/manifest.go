@@ -0,0 +1,213 @@
+package kubernetes
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"gopkg.in/yaml.v3"
+	"io"
+	"strings"
+)
+
+// Container represents the subset of a container spec we are interested in.
+type Container struct {
+	Name      string `yaml:"name"`
+	Image     string `yaml:"image"`
+	Resources struct {
+		Requests map[string]string `yaml:"requests"` // quantities (such as 1.10 or 500m) are kept as written
+		Limits   map[string]string `yaml:"limits"`
+	} `yaml:"resources"`
+
+	LivenessProbe   map[string]any `yaml:"livenessProbe"`
+	ReadinessProbe  map[string]any `yaml:"readinessProbe"`
+	StartupProbe    map[string]any `yaml:"startupProbe"`
+	SecurityContext map[string]any `yaml:"securityContext"`
+}
+
+// PodSpec represents the subset of a pod spec we are interested in.
+type PodSpec struct {
+	InitContainers  []Container    `yaml:"initContainers"`
+	Containers      []Container    `yaml:"containers"`
+	SecurityContext map[string]any `yaml:"securityContext"`
+}
+
+// Object represents a kubernetes object, with pod specs decoded from all the places that workload kinds embed them.
+// It is decoded with yaml.v3, which retains the original text of scalars decoded into strings.
+type Object struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+
+	Spec struct {
+		PodSpec `yaml:",inline"` // for Pod
+
+		Template struct {
+			Spec PodSpec `yaml:"spec"`
+		} `yaml:"template"` // for Deployment, StatefulSet, DaemonSet, ReplicaSet, ReplicationController and Job
+
+		JobTemplate struct {
+			Spec struct {
+				Template struct {
+					Spec PodSpec `yaml:"spec"`
+				} `yaml:"template"`
+			} `yaml:"spec"`
+		} `yaml:"jobTemplate"` // for CronJob
+	} `yaml:"spec"`
+}
+
+// PodSpec returns the pod spec embedded in the object, or nil if the object is not a workload.
+func (o *Object) PodSpec() *PodSpec {
+	switch o.Kind {
+	case "Pod":
+		return &o.Spec.PodSpec
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		return &o.Spec.Template.Spec
+	case "CronJob":
+		return &o.Spec.JobTemplate.Spec.Template.Spec
+	}
+	return nil
+}
+
+// ManifestScanner implements scanner.Scanner to extract facts from kubernetes manifests.
+type ManifestScanner struct{}
+
+func (m *ManifestScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && (strings.HasSuffix(file.Name, ".yaml") || strings.HasSuffix(file.Name, ".yml"))
+}
+
+func (m *ManifestScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, err
+	}
+
+	// most yaml files in a repository are not kubernetes manifests; those without the fields
+	// every kubernetes object must have are skipped before doing any (comparatively expensive) parsing
+	if !isManifest(content.Bytes()) {
+		return nil, nil
+	}
+
+	for _, doc := range documents(content.Bytes()) {
+		if !isManifest(doc) {
+			continue
+		}
+
+		// not all yaml files are kubernetes manifests (and some, like helm templates, are not even valid yaml);
+		// documents that fail to parse, or that do not look like kubernetes objects, are skipped.
+		var obj Object
+		if err = yaml.Unmarshal(doc, &obj); err != nil || obj.APIVersion == "" || obj.Kind == "" {
+			continue
+		}
+
+		facts = append(facts, objectFacts(&obj)...)
+	}
+
+	return facts, nil
+}
+
+// objectFacts returns all facts for the given kubernetes object
+func objectFacts(obj *Object) []scanner.Fact {
+	// attrs returns the attributes identifying the object, common to all facts
+	var attrs = func() map[string]any {
+		return map[string]any{"kind": obj.Kind, "name": obj.Metadata.Name, "namespace": obj.Metadata.Namespace}
+	}
+
+	var val = attrs()
+	val["api_version"] = obj.APIVersion
+	var facts = []scanner.Fact{{Key: "@kubernetes/object", Value: val}}
+
+	var spec = obj.PodSpec()
+	if spec == nil {
+		return facts
+	}
+
+	if spec.SecurityContext != nil {
+		var val = attrs()
+		val["settings"] = spec.SecurityContext
+		facts = append(facts, scanner.Fact{Key: "@kubernetes/security-context", Value: val})
+	}
+
+	var containers = func(containers []Container, init bool) {
+		for _, container := range containers {
+			var val = attrs()
+			val["container"], val["image"], val["init"] = container.Name, container.Image, init
+			if container.Resources.Requests != nil {
+				val["requests"] = container.Resources.Requests
+			}
+			if container.Resources.Limits != nil {
+				val["limits"] = container.Resources.Limits
+			}
+			facts = append(facts, scanner.Fact{Key: "@kubernetes/container", Value: val})
+
+			var probes = map[string]map[string]any{
+				"liveness": container.LivenessProbe, "readiness": container.ReadinessProbe, "startup": container.StartupProbe,
+			}
+			for typ, probe := range probes {
+				if probe == nil {
+					continue
+				}
+
+				var val = attrs()
+				val["container"], val["probe"], val["settings"] = container.Name, typ, probe
+				for _, handler := range []string{"httpGet", "tcpSocket", "exec", "grpc"} {
+					if _, ok := probe[handler]; ok {
+						val["handler"] = handler
+					}
+				}
+				facts = append(facts, scanner.Fact{Key: "@kubernetes/probe", Value: val})
+			}
+
+			if container.SecurityContext != nil {
+				var val = attrs()
+				val["container"], val["settings"] = container.Name, container.SecurityContext
+				facts = append(facts, scanner.Fact{Key: "@kubernetes/security-context", Value: val})
+			}
+		}
+	}
+
+	containers(spec.InitContainers, true)
+	containers(spec.Containers, false)
+
+	return facts
+}
+
+// isManifest is a cheap check for whether the content may contain a kubernetes object
+func isManifest(content []byte) bool {
+	return bytes.Contains(content, []byte("apiVersion:")) && bytes.Contains(content, []byte("kind:"))
+}
+
+// documents splits a (multi-document) yaml stream into individual documents
+func documents(content []byte) [][]byte {
+	var docs [][]byte
+	var current bytes.Buffer
+
+	var lines = bufio.NewScanner(bytes.NewReader(content))
+	lines.Buffer(nil, len(content)+1) // allow lines as long as the whole content
+	for lines.Scan() {
+		if line := lines.Text(); strings.HasPrefix(line, "---") || strings.HasPrefix(line, "...") {
+			docs, current = append(docs, append([]byte(nil), current.Bytes()...)), bytes.Buffer{}
+			continue
+		}
+		current.Write(lines.Bytes())
+		current.WriteByte('\n')
+	}
+
+	return append(docs, current.Bytes())
+}
+
+// register the ManifestScanner with scanner registry
+func init() { scanner.Register("kubernetes/manifest", &ManifestScanner{}) }
@@ -0,0 +1,46 @@
+package terraform
+
+import (
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/mergestat/kyc/pkg/scanner"
+	"path"
+)
+
+// LockScanner implements scanner.Scanner to extract facts from terraform dependency lock (.terraform.lock.hcl) files.
+type LockScanner struct{}
+
+func (l *LockScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && path.Base(file.Name) == ".terraform.lock.hcl"
+}
+
+func (l *LockScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+
+	var body *hclsyntax.Body
+	var content []byte
+	if body, content, err = parse(file); err != nil {
+		return nil, err
+	}
+
+	// for each provider, emit a fact
+	for _, block := range body.Blocks {
+		if block.Type != "provider" {
+			continue
+		}
+
+		var val = map[string]any{"provider": label(block, 0)}
+		for _, name := range []string{"version", "constraints", "hashes"} {
+			if attr, ok := block.Body.Attributes[name]; ok {
+				val[name] = value(attr.Expr, content)
+			}
+		}
+		facts = append(facts, scanner.Fact{Key: "@terraform/provider-locked", Value: val})
+	}
+
+	return facts, nil
+}
+
+// register the LockScanner with scanner registry
+func init() { scanner.Register("terraform/lock", &LockScanner{}) }
@@ -0,0 +1,179 @@
+package terraform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/mergestat/kyc/pkg/scanner"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"io"
+	"strings"
+)
+
+// backendKeys lists the backend configuration attributes that are included in facts. Backends commonly
+// carry credentials (access keys, tokens, connection strings and the like), so only known non-secret
+// attributes are reported.
+var backendKeys = map[string]bool{
+	"bucket": true, "key": true, "region": true, "prefix": true, "path": true, "workspace_dir": true,
+	"workspace_key_prefix": true, "dynamodb_table": true, "encrypt": true, "storage_account_name": true,
+	"container_name": true, "resource_group_name": true, "organization": true, "hostname": true,
+}
+
+// ConfigScanner implements scanner.Scanner to extract facts from terraform configuration (*.tf) files.
+type ConfigScanner struct{}
+
+func (c *ConfigScanner) Supports(file *object.File) bool {
+	return file.Mode.IsFile() && strings.HasSuffix(file.Name, ".tf")
+}
+
+func (c *ConfigScanner) Scan(_ context.Context, file *object.File, _ ...string) (_ []scanner.Fact, err error) {
+	var facts []scanner.Fact
+	var emit = func(key string, val map[string]any) { facts = append(facts, scanner.Fact{Key: key, Value: val}) }
+
+	var body *hclsyntax.Body
+	var content []byte
+	if body, content, err = parse(file); err != nil {
+		return nil, err
+	}
+
+	for _, block := range body.Blocks {
+		switch block.Type {
+		case "terraform":
+			if attr, ok := block.Body.Attributes["required_version"]; ok {
+				emit("@terraform/required-version", map[string]any{"constraint": value(attr.Expr, content)})
+			}
+
+			for _, nested := range block.Body.Blocks {
+				switch nested.Type {
+				case "required_providers":
+					for name, attr := range nested.Body.Attributes {
+						var val = map[string]any{"name": name}
+						if obj, ok := attr.Expr.(*hclsyntax.ObjectConsExpr); ok {
+							// evaluate the items one at a time, as some (like configuration_aliases)
+							// reference other objects and cannot be evaluated statically
+							for _, item := range obj.Items {
+								if k := key(item.KeyExpr, content); k == "source" || k == "version" {
+									val[k] = value(item.ValueExpr, content)
+								}
+							}
+						} else { // legacy (pre-0.13) syntax, where only the version is specified
+							val["version"] = value(attr.Expr, content)
+						}
+						emit("@terraform/required-provider", val)
+					}
+				case "backend":
+					emit("@terraform/backend", map[string]any{"type": label(nested, 0), "config": attributes(nested.Body, content, backendKeys)})
+				case "cloud":
+					emit("@terraform/backend", map[string]any{"type": "cloud", "config": attributes(nested.Body, content, backendKeys)})
+				}
+			}
+
+		case "provider":
+			var val = map[string]any{"name": label(block, 0)}
+			if attr, ok := block.Body.Attributes["alias"]; ok {
+				val["alias"] = value(attr.Expr, content)
+			}
+			emit("@terraform/provider", val)
+
+		case "module":
+			var val = map[string]any{"name": label(block, 0)}
+			for _, name := range []string{"source", "version"} {
+				if attr, ok := block.Body.Attributes[name]; ok {
+					val[name] = value(attr.Expr, content)
+				}
+			}
+			emit("@terraform/module", val)
+
+		case "resource", "data":
+			// unless overridden with the provider meta-argument, the provider is derived from the type's prefix
+			var typ = label(block, 0)
+			var provider, _, _ = strings.Cut(typ, "_")
+			if attr, ok := block.Body.Attributes["provider"]; ok {
+				provider = string(attr.Expr.Range().SliceBytes(content))
+			}
+
+			emit("@terraform/"+block.Type, map[string]any{"type": typ, "name": label(block, 1), "provider": provider})
+		}
+	}
+
+	return facts, nil
+}
+
+// parse reads and parses the hcl file, returning its body along with the raw content
+func parse(file *object.File) (_ *hclsyntax.Body, _ []byte, err error) {
+	// read the file content to parse
+	var reader io.ReadCloser
+	if reader, err = file.Reader(); err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	var content bytes.Buffer
+	if _, err = io.Copy(&content, reader); err != nil {
+		return nil, nil, err
+	}
+
+	var f, diags = hclsyntax.ParseConfig(content.Bytes(), file.Name, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	return f.Body.(*hclsyntax.Body), content.Bytes(), nil
+}
+
+// label returns the block's label at the given index, or an empty string if there is no such label
+func label(block *hclsyntax.Block, i int) string {
+	if i < len(block.Labels) {
+		return block.Labels[i]
+	}
+	return ""
+}
+
+// attributes returns the values of the allowed attributes in the body
+func attributes(body *hclsyntax.Body, content []byte, allowed map[string]bool) map[string]any {
+	var result = make(map[string]any)
+	for name, attr := range body.Attributes {
+		if allowed[name] {
+			result[name] = value(attr.Expr, content)
+		}
+	}
+	return result
+}
+
+// key returns the name of an object's key, which can either be a bare identifier or a quoted string
+func key(expr hclsyntax.Expression, content []byte) string {
+	if keyword := hcl.ExprAsKeyword(expr); keyword != "" {
+		return keyword
+	}
+	var k, _ = value(expr, content).(string)
+	return k
+}
+
+// value evaluates the expression (without any variables or functions in scope) and returns the result
+// as a plain go value. Expressions that cannot be evaluated statically are returned as their source text.
+func value(expr hclsyntax.Expression, content []byte) any {
+	var source = string(expr.Range().SliceBytes(content))
+
+	var val, diags = expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() {
+		return source
+	}
+
+	var buf []byte
+	var err error
+	if buf, err = (ctyjson.SimpleJSONValue{Value: val}).MarshalJSON(); err != nil {
+		return source
+	}
+
+	var result any
+	if err = json.Unmarshal(buf, &result); err != nil {
+		return source
+	}
+	return result
+}
+
+// register the ConfigScanner with scanner registry
+func init() { scanner.Register("terraform/config", &ConfigScanner{}) }
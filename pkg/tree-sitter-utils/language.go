@@ -0,0 +1,77 @@
+package tree_sitter_utils
+
+import (
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/bash"
+	"github.com/smacker/go-tree-sitter/dockerfile"
+	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/hcl"
+	"github.com/smacker/go-tree-sitter/java"
+	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/kotlin"
+	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/ruby"
+	"github.com/smacker/go-tree-sitter/rust"
+	"github.com/smacker/go-tree-sitter/toml"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
+	"github.com/smacker/go-tree-sitter/typescript/typescript"
+	"github.com/smacker/go-tree-sitter/yaml"
+	"path"
+	"strings"
+)
+
+// collection of all registered languages, keyed by name
+var languages = make(map[string]*sitter.Language)
+
+// mapping of file extensions (and exact file names) to the name of the language
+var extensions = make(map[string]string)
+
+// RegisterLanguage registers a new language in the global registry of languages. Files with names that either end
+// with one of the given extensions (such as ".go") or are equal to one of them (such as "Dockerfile") are associated
+// with the language.
+func RegisterLanguage(name string, lang *sitter.Language, exts ...string) {
+	languages[name] = lang
+	for _, ext := range exts {
+		extensions[ext] = name
+	}
+}
+
+// GetLanguage returns the tree-sitter language with the given name, or nil if the language is not registered.
+func GetLanguage(name string) *sitter.Language { return languages[name] }
+
+// LanguageForFile returns the name of the language associated with the given file name (or path),
+// or an empty string if the file doesn't match any registered language.
+func LanguageForFile(name string) string {
+	var base = path.Base(name)
+	if lang, ok := extensions[base]; ok {
+		return lang
+	}
+
+	// try all extensions, from the longest (such as .d.ts) to the shortest (such as .ts)
+	for i := strings.IndexByte(base, '.'); i >= 0; i = strings.IndexByte(base, '.') {
+		if lang, ok := extensions[base[i:]]; ok {
+			return lang
+		}
+		base = base[i+1:]
+	}
+
+	return ""
+}
+
+// register all built-in languages
+func init() {
+	RegisterLanguage("bash", bash.GetLanguage(), ".sh", ".bash")
+	RegisterLanguage("dockerfile", dockerfile.GetLanguage(), "Dockerfile", "Containerfile", ".dockerfile")
+	RegisterLanguage("go", golang.GetLanguage(), ".go")
+	RegisterLanguage("hcl", hcl.GetLanguage(), ".hcl", ".tf", ".tfvars")
+	RegisterLanguage("java", java.GetLanguage(), ".java")
+	RegisterLanguage("javascript", javascript.GetLanguage(), ".js", ".mjs", ".cjs", ".jsx")
+	RegisterLanguage("kotlin", kotlin.GetLanguage(), ".kt", ".kts")
+	RegisterLanguage("python", python.GetLanguage(), ".py")
+	RegisterLanguage("ruby", ruby.GetLanguage(), ".rb", ".gemspec", "Gemfile", "Rakefile")
+	RegisterLanguage("rust", rust.GetLanguage(), ".rs")
+	RegisterLanguage("toml", toml.GetLanguage(), ".toml")
+	RegisterLanguage("tsx", tsx.GetLanguage(), ".tsx")
+	RegisterLanguage("typescript", typescript.GetLanguage(), ".ts", ".mts", ".cts")
+	RegisterLanguage("yaml", yaml.GetLanguage(), ".yml", ".yaml")
+}
@@ -0,0 +1,42 @@
+package tree_sitter_utils
+
+import (
+	"context"
+	sitter "github.com/smacker/go-tree-sitter"
+	"sync"
+)
+
+// pools of reusable parsers, keyed by the language they are configured for
+var pools sync.Map // map[*sitter.Language]*sync.Pool
+
+// pool returns the pool of parsers for the given language, creating it if required.
+func pool(lang *sitter.Language) *sync.Pool {
+	if p, ok := pools.Load(lang); ok {
+		return p.(*sync.Pool)
+	}
+
+	var p, _ = pools.LoadOrStore(lang, &sync.Pool{
+		New: func() any { var parser = sitter.NewParser(); parser.SetLanguage(lang); return parser },
+	})
+	return p.(*sync.Pool)
+}
+
+// Parse parses the content using the given language, returning the resulting syntax tree.
+// It is safe to call Parse concurrently; parsers are pooled and reused across calls (made with non-cancellable contexts).
+func Parse(ctx context.Context, lang *sitter.Language, content []byte) (_ *sitter.Tree, err error) {
+	var p = pool(lang)
+	var parser = p.Get().(*sitter.Parser)
+
+	// for cancellable contexts, go-tree-sitter sets the parser's cancellation flag from a separate goroutine once ctx is done.
+	// The flag is only reset when it interrupts a parse, but the goroutine may just as well set it after the parse completes,
+	// leaving the parser to fail its next use; as there's no way to reset it ourselves, such parsers are not reused.
+	var tree *sitter.Tree
+	if tree, err = parser.ParseCtx(ctx, nil, content); err != nil {
+		return nil, err
+	}
+	if ctx.Done() == nil {
+		p.Put(parser)
+	}
+
+	return tree, nil
+}
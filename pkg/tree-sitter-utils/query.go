@@ -0,0 +1,50 @@
+package tree_sitter_utils
+
+import (
+	"context"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Capture represents a single node captured by a tree-sitter query.
+type Capture struct {
+	Pattern int          // index of the pattern (in the query) that produced the capture
+	Name    string       // name of the capture, without the leading @
+	Node    *sitter.Node // the captured node
+}
+
+// Query parses the content using the given language, and executes the query against the resulting tree,
+// returning all captures (from all matches) in the order they were matched. Predicates, such as #eq? and #match?,
+// are applied to each match before its captures are returned.
+func Query(ctx context.Context, lang *sitter.Language, content []byte, pattern string) (_ []Capture, err error) {
+	var query *sitter.Query
+	if query, err = sitter.NewQuery([]byte(pattern), lang); err != nil {
+		return nil, err
+	}
+	defer query.Close()
+
+	var tree *sitter.Tree
+	if tree, err = Parse(ctx, lang, content); err != nil {
+		return nil, err
+	}
+	// the tree is not closed here, as the returned captures refer to its nodes
+
+	var cursor = sitter.NewQueryCursor()
+	defer cursor.Close()
+	cursor.Exec(query, tree.RootNode())
+
+	var captures []Capture
+	for {
+		match, ok := cursor.NextMatch()
+		if !ok {
+			break
+		}
+
+		match = cursor.FilterPredicates(match, content)
+		for _, capture := range match.Captures {
+			var name = query.CaptureNameForId(capture.Index)
+			captures = append(captures, Capture{Pattern: int(match.PatternIndex), Name: name, Node: capture.Node})
+		}
+	}
+
+	return captures, nil
+}
@@ -1,37 +1,103 @@
 package tree_sitter_utils
 
-import sitter "github.com/smacker/go-tree-sitter"
+import (
+	"context"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Action is returned by a Visitor to control how the traversal proceeds.
+type Action int
+
+const (
+	Continue     Action = iota // continue traversal as usual
+	SkipChildren               // do not visit the children of the current node (only meaningful in pre-order traversal)
+	Stop                       // stop the traversal immediately
+)
+
+// Visitor is invoked for each node visited during traversal.
+type Visitor func(*sitter.Node) Action
+
+// Filter returns a Visitor that invokes fn only for nodes for which the given predicate function returns true.
+// All other nodes are skipped over, but their children are still visited.
+func Filter(pred func(*sitter.Node) bool, fn Visitor) Visitor {
+	return func(node *sitter.Node) Action {
+		if pred(node) {
+			return fn(node)
+		}
+		return Continue
+	}
+}
 
 // Walk traverses the (sub-)tree in post-order traversal (visiting all child nodes before the parent node),
 // invoking the provided callback function for each node.
 func Walk(node *sitter.Node, fn func(*sitter.Node)) {
+	_ = PostOrder(context.Background(), node, func(node *sitter.Node) Action { fn(node); return Continue })
+}
+
+// PreOrder traverses the (sub-)tree in pre-order traversal (visiting the parent node before any of its children),
+// invoking the provided visitor for each node. The visitor may return SkipChildren to skip the subtree
+// of the current node, or Stop to end the traversal. The traversal also stops if the context is cancelled,
+// in which case the context's error is returned.
+func PreOrder(ctx context.Context, node *sitter.Node, fn Visitor) error {
 	var cursor = sitter.NewTreeCursor(node)
 	defer cursor.Close()
 
-	// Walk() traverses the tree using recursion.
-	// It attempts to locate the left-most leaf node, and then work its way side-ways (to next sibling).
-	// When all siblings are handled, it moves back to the parent node.
-	var walk func(*sitter.TreeCursor)
-	walk = func(cursor *sitter.TreeCursor) {
-		if cursor.GoToFirstChild() {
-			walk(cursor) // dive into left-most / first child's subtree
+	// the traversal is iterative (rather than recursive) so that deeply nested trees cannot overflow the stack.
+	// the cursor is rooted at the given node, and so it never moves to the node's siblings or parent.
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var action = fn(cursor.CurrentNode())
+		if action == Stop {
+			return nil
+		}
+
+		if action != SkipChildren && cursor.GoToFirstChild() {
+			continue // dive into the first child's subtree
+		}
+
+		// no (more) children to visit; move to the next sibling,
+		// climbing up until we find an ancestor that has one
+		for !cursor.GoToNextSibling() {
+			if !cursor.GoToParent() {
+				return nil // back at the root; we are done!
+			}
 		}
+	}
+}
 
-		// after handling the children node(s) (or not if we are leaf),
-		// invoke the user-supplied callback function for the current node.
-		fn(cursor.CurrentNode())
+// PostOrder traverses the (sub-)tree in post-order traversal (visiting all child nodes before the parent node),
+// invoking the provided visitor for each node. The visitor may return Stop to end the traversal. The traversal also
+// stops if the context is cancelled, in which case the context's error is returned.
+func PostOrder(ctx context.Context, node *sitter.Node, fn Visitor) error {
+	var cursor = sitter.NewTreeCursor(node)
+	defer cursor.Close()
 
-		// attempt to traverse the subtree of the next sibling,
-		// or goto parent node if there aren't any siblings left.
-		if hasSibling := cursor.GoToNextSibling(); hasSibling {
-			walk(cursor)
-		} else {
-			cursor.GoToParent()
+	// descend locates the left-most leaf node in the current node's subtree
+	var descend = func() {
+		for cursor.GoToFirstChild() {
 		}
 	}
 
-	// kick-start traversal!
-	walk(cursor)
+	descend()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// all children of the current node (if any) have already been visited
+		if fn(cursor.CurrentNode()) == Stop {
+			return nil
+		}
+
+		if cursor.GoToNextSibling() {
+			descend() // handle the next sibling's subtree before the sibling itself
+		} else if !cursor.GoToParent() {
+			return nil // the root node was the last one visited; we are done!
+		}
+	}
 }
 
 // Find returns a list of all nodes for which the given predicate function returns true.
@@ -44,3 +110,17 @@ func Find(node *sitter.Node, fn func(*sitter.Node) bool) []*sitter.Node {
 	})
 	return nodes
 }
+
+// FindFirst returns the first node (in pre-order) for which the given predicate function returns true,
+// or nil if there is no such node. Unlike Find, it stops the traversal as soon as a match is found.
+func FindFirst(node *sitter.Node, fn func(*sitter.Node) bool) *sitter.Node {
+	var found *sitter.Node
+	_ = PreOrder(context.Background(), node, func(node *sitter.Node) Action {
+		if fn(node) {
+			found = node
+			return Stop
+		}
+		return Continue
+	})
+	return found
+}
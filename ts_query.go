@@ -0,0 +1,157 @@
+package kyc
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	utils "github.com/mergestat/kyc/pkg/tree-sitter-utils"
+	sitter "github.com/smacker/go-tree-sitter"
+	"go.riyazali.net/sqlite"
+)
+
+const (
+	ColumnTsQueryLanguage  = iota // name of the tree-sitter language used to parse the source
+	ColumnTsQuerySource           // source code to run the query against
+	ColumnTsQueryQuery            // tree-sitter query to execute
+	ColumnTsQueryPattern          // index of the query pattern that produced the capture
+	ColumnTsQueryCapture          // name of the capture
+	ColumnTsQueryNodeType         // type of the captured node
+	ColumnTsQueryText             // source text of the captured node
+	ColumnTsQueryStartByte        // byte offset at which the captured node starts
+	ColumnTsQueryEndByte          // byte offset at which the captured node ends
+	ColumnTsQueryStartLine        // (1-based) line number at which the captured node starts
+	ColumnTsQueryEndLine          // (1-based) line number at which the captured node ends
+)
+
+// TsQueryModule implements sqlite.Module interface for ts_query() table-valued function.
+type TsQueryModule struct{}
+
+func (mod *TsQueryModule) Connect(_ *sqlite.Conn, _ []string, declare func(string) error) (_ sqlite.VirtualTable, err error) {
+	const schema = `
+		CREATE TABLE ts_query (
+			language		HIDDEN,
+			source			HIDDEN,
+			query			HIDDEN,
+			pattern			INT,
+			capture			TEXT,
+			node_type		TEXT,
+			text			TEXT,
+			start_byte		INT,
+			end_byte		INT,
+			start_line		INT,
+			end_line		INT
+		)`
+
+	if err = declare(schema); err != nil {
+		return nil, err
+	}
+
+	return &TsQueryTable{}, nil
+}
+
+// TsQueryTable implements sqlite.VirtualTable interface for ts_query() table-valued function.
+type TsQueryTable struct{}
+
+func (table *TsQueryTable) BestIndex(input *sqlite.IndexInfoInput) (*sqlite.IndexInfoOutput, error) {
+	var argv = 1
+	var bitmap []byte
+	var set = func(op, col int) { bitmap = append(bitmap, byte(op<<4|col)) }
+
+	var output = &sqlite.IndexInfoOutput{
+		ConstraintUsage: make([]*sqlite.ConstraintUsage, len(input.Constraints)),
+	}
+
+	// all of language, source and query arguments are required
+	var constrained = make(map[int]bool)
+
+	for i, cons := range input.Constraints {
+		switch col, op := cons.ColumnIndex, cons.Op; col {
+		case ColumnTsQueryLanguage, ColumnTsQuerySource, ColumnTsQueryQuery:
+			{
+				if op == sqlite.INDEX_CONSTRAINT_EQ && cons.Usable {
+					output.ConstraintUsage[i] = &sqlite.ConstraintUsage{ArgvIndex: argv, Omit: true}
+					constrained[col], argv = true, argv+1
+					set(OpEqual, col)
+				}
+			}
+		}
+	}
+
+	if !constrained[ColumnTsQueryLanguage] || !constrained[ColumnTsQuerySource] || !constrained[ColumnTsQueryQuery] {
+		return nil, sqlite.Error(sqlite.SQLITE_CONSTRAINT, "language, source and query are required")
+	}
+
+	// pass the bitmap as string to xFilter routine
+	output.IndexString = base64.StdEncoding.EncodeToString(bitmap)
+
+	return output, nil
+}
+
+func (table *TsQueryTable) Open() (sqlite.VirtualCursor, error) { return &TsQueryCursor{}, nil }
+func (table *TsQueryTable) Disconnect() error                   { return nil }
+func (table *TsQueryTable) Destroy() error                      { return nil }
+
+// TsQueryCursor implements sqlite.VirtualCursor interface for ts_query() table-valued function.
+type TsQueryCursor struct {
+	source   []byte
+	pos      int
+	captures []utils.Capture
+}
+
+func (cur *TsQueryCursor) Filter(_ int, str string, values ...sqlite.Value) (err error) {
+	var ctx = context.Background()
+
+	var lang *sitter.Language
+	var source []byte
+	var query string
+
+	var bitmap, _ = base64.StdEncoding.DecodeString(str)
+	for n, val := range values {
+		switch col := int(bitmap[n] & 0b00001111); col {
+		case ColumnTsQueryLanguage:
+			if lang = utils.GetLanguage(val.Text()); lang == nil {
+				return sqlite.Error(sqlite.SQLITE_ERROR, fmt.Sprintf("unsupported language %q", val.Text()))
+			}
+		case ColumnTsQuerySource:
+			source = append([]byte(nil), val.Blob()...) // copy, as the value is not retained by sqlite beyond this call
+		case ColumnTsQueryQuery:
+			query = val.Text()
+		}
+	}
+
+	if cur.captures, err = utils.Query(ctx, lang, source, query); err != nil {
+		return sqlite.Error(sqlite.SQLITE_ERROR, err.Error())
+	}
+
+	cur.source, cur.pos = source, 0
+	return nil
+}
+
+func (cur *TsQueryCursor) Column(context *sqlite.VirtualTableContext, pos int) error {
+	var capture = cur.captures[cur.pos]
+
+	switch pos {
+	case ColumnTsQueryPattern:
+		context.ResultInt(capture.Pattern)
+	case ColumnTsQueryCapture:
+		context.ResultText(capture.Name)
+	case ColumnTsQueryNodeType:
+		context.ResultText(capture.Node.Type())
+	case ColumnTsQueryText:
+		context.ResultText(capture.Node.Content(cur.source))
+	case ColumnTsQueryStartByte:
+		context.ResultInt(int(capture.Node.StartByte()))
+	case ColumnTsQueryEndByte:
+		context.ResultInt(int(capture.Node.EndByte()))
+	case ColumnTsQueryStartLine:
+		context.ResultInt(int(capture.Node.StartPoint().Row) + 1)
+	case ColumnTsQueryEndLine:
+		context.ResultInt(int(capture.Node.EndPoint().Row) + 1)
+	}
+	return nil
+}
+
+func (cur *TsQueryCursor) Next() error           { cur.pos += 1; return nil }
+func (cur *TsQueryCursor) Rowid() (int64, error) { return int64(cur.pos), nil }
+func (cur *TsQueryCursor) Eof() bool             { return cur.pos >= len(cur.captures) }
+func (cur *TsQueryCursor) Close() error          { return nil }